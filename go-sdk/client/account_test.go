@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type stubAccountServer struct {
+	mmnpb.UnimplementedAccountServiceServer
+	inFlight, maxInFlight atomic.Int32
+	release               chan struct{}
+}
+
+func (s *stubAccountServer) GetAccount(ctx context.Context, req *mmnpb.GetAccountRequest) (*mmnpb.GetAccountResponse, error) {
+	n := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		m := s.maxInFlight.Load()
+		if n <= m || s.maxInFlight.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	<-s.release
+
+	switch req.Address {
+	case "missing":
+		return nil, status.Error(codes.NotFound, "account not found")
+	case "down":
+		return nil, status.Error(codes.Unavailable, "overloaded")
+	}
+	return &mmnpb.GetAccountResponse{Address: req.Address, Balance: "10", Nonce: 3}, nil
+}
+
+func newStubAccountClient(t *testing.T, srv *stubAccountServer, cfg Config) *MmnClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	mmnpb.RegisterAccountServiceServer(gs, srv)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &MmnClient{cfg: cfg, conn: conn, accClient: mmnpb.NewAccountServiceClient(conn)}
+}
+
+func TestClient_GetAccount_NotFound(t *testing.T) {
+	srv := &stubAccountServer{release: make(chan struct{})}
+	close(srv.release)
+	client := newStubAccountClient(t, srv, Config{})
+
+	acc, err := client.GetAccount(context.Background(), "missing")
+	if !errors.Is(err, ErrAccountNotFound) {
+		t.Fatalf("GetAccount() error = %v, want ErrAccountNotFound", err)
+	}
+	if acc.Balance != nil || acc.Address != "" {
+		t.Errorf("GetAccount() = %+v, want zero Account on error", acc)
+	}
+
+	if _, err := client.GetAccount(context.Background(), "down"); errors.Is(err, ErrAccountNotFound) || !IsRetryable(err) {
+		t.Errorf("GetAccount() error = %v, want retryable transport error", err)
+	}
+}
+
+func TestClient_GetAccounts(t *testing.T) {
+	srv := &stubAccountServer{release: make(chan struct{})}
+	client := newStubAccountClient(t, srv, Config{BatchConcurrency: 2})
+
+	addrs := []string{"a", "missing", "b", "c", "down"}
+	done := make(chan struct{})
+	var results []AccountResult
+	go func() {
+		defer close(done)
+		results, _ = client.GetAccounts(context.Background(), addrs...)
+	}()
+	for range addrs {
+		srv.release <- struct{}{}
+	}
+	<-done
+
+	if got := srv.maxInFlight.Load(); got > 2 {
+		t.Errorf("max in-flight requests = %d, want <= 2", got)
+	}
+	if len(results) != len(addrs) {
+		t.Fatalf("GetAccounts() returned %d results, want %d", len(results), len(addrs))
+	}
+	for i, r := range results {
+		if r.Address != addrs[i] {
+			t.Errorf("results[%d].Address = %q, want %q", i, r.Address, addrs[i])
+		}
+	}
+	if results[0].Err != nil || results[0].Account.Nonce != 3 {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, ErrAccountNotFound) {
+		t.Errorf("results[1].Err = %v, want ErrAccountNotFound", results[1].Err)
+	}
+	if !errors.Is(results[4].Err, ErrNodeUnavailable) {
+		t.Errorf("results[4].Err = %v, want ErrNodeUnavailable", results[4].Err)
+	}
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+
+	"filippo.io/edwards25519"
+	"github.com/mr-tron/base58"
+)
+
+var (
+	ErrUnreachableAddress = errors.New("domain: address is not an ed25519 public key")
+	ErrUnknownLabel       = errors.New("addressbook: unknown label")
+)
+
+// AddressKind is how an address was derived.
+type AddressKind string
+
+const (
+	// AddressKindKey is the base58 ed25519 public key of a key pair.
+	AddressKindKey AddressKind = "key"
+	// AddressKindUserID is GenerateAddress of a user ID, controlled through ZK transfers.
+	AddressKindUserID AddressKind = "user_id"
+)
+
+// Address is a parsed 32-byte account address. The zero Address is invalid.
+type Address struct {
+	raw  [addressDecodedExpectedLength]byte
+	kind AddressKind
+	set  bool
+}
+
+// ParseAddress decodes s and classifies it. A point that is not on the edwards25519
+// curve cannot be a key, so it is AddressKindUserID. A point on the curve is classified
+// AddressKindKey, although about half of all user-ID hashes are also on the curve; use
+// AddressFromUserID when the derivation is known.
+func ParseAddress(s string) (Address, error) {
+	decoded, err := base58.Decode(s)
+	if err != nil || len(decoded) != addressDecodedExpectedLength {
+		return Address{}, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+	a := Address{set: true, kind: AddressKindUserID}
+	copy(a.raw[:], decoded)
+	if onCurve(decoded) {
+		a.kind = AddressKindKey
+	}
+	return a, nil
+}
+
+// MustParseAddress is ParseAddress that panics on error, for constants and tests.
+func MustParseAddress(s string) Address {
+	a, err := ParseAddress(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// AddressFromPubKey is the address of pub, which must be an ed25519 public key.
+func AddressFromPubKey(pub ed25519.PublicKey) (Address, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return Address{}, fmt.Errorf("%w: public key has %d bytes, want %d", ErrInvalidAddress, len(pub), ed25519.PublicKeySize)
+	}
+	a := Address{set: true, kind: AddressKindKey}
+	copy(a.raw[:], pub)
+	return a, nil
+}
+
+// AddressFromUserID is the address GenerateAddress derives from userID.
+func AddressFromUserID(userID string) Address {
+	return Address{raw: sha256.Sum256([]byte(userID)), kind: AddressKindUserID, set: true}
+}
+
+func (a Address) String() string {
+	if !a.set {
+		return ""
+	}
+	return base58.Encode(a.raw[:])
+}
+
+func (a Address) Bytes() []byte {
+	return append([]byte(nil), a.raw[:]...)
+}
+
+func (a Address) Kind() AddressKind {
+	return a.kind
+}
+
+func (a Address) IsZero() bool {
+	return !a.set
+}
+
+// IsKey reports whether a can hold funds sent with TxTypeTransferByKey signatures,
+// that is whether it is a valid ed25519 public key.
+func (a Address) IsKey() bool {
+	return a.set && onCurve(a.raw[:])
+}
+
+// PubKey returns a as an ed25519 public key, or an error if it is not on the curve.
+func (a Address) PubKey() (ed25519.PublicKey, error) {
+	if !a.IsKey() {
+		return nil, fmt.Errorf("%w: %s", ErrUnreachableAddress, a)
+	}
+	return ed25519.PublicKey(a.Bytes()), nil
+}
+
+// Equal compares the address bytes; the kind is ignored.
+func (a Address) Equal(b Address) bool {
+	return a.set == b.set && a.raw == b.raw
+}
+
+// MarshalText encodes a as base58. encoding/json uses it, so an Address is a JSON string.
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses base58 text with ParseAddress. Empty text yields the zero Address.
+func (a *Address) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*a = Address{}
+		return nil
+	}
+	parsed, err := ParseAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+func onCurve(b []byte) bool {
+	_, err := new(edwards25519.Point).SetBytes(b)
+	return err == nil
+}
+
+// AddressBook maps labels to addresses. It marshals to a JSON object of base58 strings.
+type AddressBook map[string]Address
+
+// Resolve returns the address labeled s, or parses s as an address.
+func (b AddressBook) Resolve(s string) (Address, error) {
+	if a, ok := b[s]; ok {
+		return a, nil
+	}
+	a, err := ParseAddress(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w or %w", ErrUnknownLabel, err)
+	}
+	return a, nil
+}
+
+// Labels returns the labels of b in sorted order.
+func (b AddressBook) Labels() []string {
+	labels := make([]string, 0, len(b))
+	for l := range b {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	return labels
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/mr-tron/base58"
+)
+
+// offCurveUserID returns a user ID whose GenerateAddress is not an ed25519 point.
+func offCurveUserID(t *testing.T) string {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("user-%d", i)
+		if !AddressFromUserID(id).IsKey() {
+			return id
+		}
+	}
+	t.Fatal("no off-curve user ID found")
+	return ""
+}
+
+func TestParseAddress(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	userAddr := GenerateAddress(offCurveUserID(t))
+
+	key, err := ParseAddress(base58.Encode(pub))
+	fromPub, _ := AddressFromPubKey(pub)
+	if err != nil || key.Kind() != AddressKindKey || !key.Equal(fromPub) {
+		t.Errorf("ParseAddress(pubkey) = %v %s, %v", key, key.Kind(), err)
+	}
+	if _, err := AddressFromPubKey(pub[:31]); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("AddressFromPubKey(31 bytes) error = %v, want ErrInvalidAddress", err)
+	}
+	user, err := ParseAddress(userAddr)
+	if err != nil || user.Kind() != AddressKindUserID || user.IsKey() {
+		t.Errorf("ParseAddress(user ID address) = %v %s, %v", user, user.Kind(), err)
+	}
+	if _, err := user.PubKey(); !errors.Is(err, ErrUnreachableAddress) {
+		t.Errorf("PubKey() of user ID address error = %v", err)
+	}
+	if _, err := ParseAddress("not-base58-0OIl"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("ParseAddress(garbage) error = %v", err)
+	}
+
+	var doc struct {
+		To   Address     `json:"to"`
+		None Address     `json:"none"`
+		Book AddressBook `json:"book"`
+	}
+	doc.To = key
+	doc.Book = AddressBook{"treasury": user}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(`{"to":%q,"none":"","book":{"treasury":%q}}`, key, userAddr)
+	if string(data) != want {
+		t.Errorf("json = %s, want %s", data, want)
+	}
+	doc.To, doc.Book = Address{}, nil
+	if err := json.Unmarshal(data, &doc); err != nil || !doc.To.Equal(key) || !doc.None.IsZero() {
+		t.Errorf("json round trip = %+v, %v", doc, err)
+	}
+	if got, err := doc.Book.Resolve("treasury"); err != nil || got.String() != userAddr {
+		t.Errorf("Resolve(label) = %v, %v", got, err)
+	}
+	if _, err := doc.Book.Resolve("payroll"); !errors.Is(err, ErrUnknownLabel) {
+		t.Errorf("Resolve(unknown) error = %v", err)
+	}
+}
+
+func TestBuildTransferTx_Strict(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	sender := base58.Encode(pub)
+	userAddr := GenerateAddress(offCurveUserID(t))
+
+	if _, err := BuildTransferTx(TxTypeTransferByKey, sender, userAddr, uint256.NewInt(1), 1, 0, "", nil, "", ""); err != nil {
+		t.Errorf("BuildTransferTx() non-strict error = %v", err)
+	}
+	_, err := BuildTransferTx(TxTypeTransferByKey, sender, userAddr, uint256.NewInt(1), 1, 0, "", nil, "", "", WithStrictAddresses())
+	if !errors.Is(err, ErrUnreachableAddress) {
+		t.Errorf("BuildTransferTx() strict to user ID address error = %v", err)
+	}
+	if _, err := BuildTransferTx(TxTypeTransferByZk, userAddr, userAddr, uint256.NewInt(1), 1, 0, "", nil, "", "", WithStrictAddresses()); err != nil {
+		t.Errorf("BuildTransferTx() strict ZK transfer error = %v", err)
+	}
+}
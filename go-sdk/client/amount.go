@@ -0,0 +1,66 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/holiman/uint256"
+)
+
+var ErrDecimalsMismatch = errors.New("domain: node decimals differ from NATIVE_DECIMAL")
+
+// effectiveDecimals returns the scale to use for a node reported decimals value.
+// Nodes that predate the decimals field report 0, which falls back to NATIVE_DECIMAL.
+func effectiveDecimals(decimals uint32) uint32 {
+	if decimals == 0 {
+		return NATIVE_DECIMAL
+	}
+	return decimals
+}
+
+// CheckDecimals returns ErrDecimalsMismatch when a node reports a scale other than NATIVE_DECIMAL.
+// An unset (zero) value is not treated as a mismatch.
+func CheckDecimals(decimals uint32) error {
+	if decimals == 0 || decimals == NATIVE_DECIMAL {
+		return nil
+	}
+	return fmt.Errorf("%w: node=%d sdk=%d", ErrDecimalsMismatch, decimals, NATIVE_DECIMAL)
+}
+
+// FormatAmount renders a base-unit amount as a decimal string, e.g. 1500000 with 6 decimals is "1.5".
+func FormatAmount(amount *uint256.Int, decimals uint32) string {
+	raw := Uint256ToString(amount)
+	scale := int(effectiveDecimals(decimals))
+
+	if len(raw) <= scale {
+		raw = strings.Repeat("0", scale-len(raw)+1) + raw
+	}
+	whole, frac := raw[:len(raw)-scale], strings.TrimRight(raw[len(raw)-scale:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// ParseAmount converts a decimal string such as "1.5" into base units using the given decimals.
+func ParseAmount(value string, decimals uint32) (*uint256.Int, error) {
+	scale := int(effectiveDecimals(decimals))
+
+	whole, frac, _ := strings.Cut(strings.TrimSpace(value), ".")
+	if whole == "" && frac == "" {
+		return nil, ErrInvalidAmount
+	}
+	if len(frac) > scale {
+		return nil, fmt.Errorf("%w: %q has more than %d fractional digits", ErrInvalidAmount, value, scale)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	amount, err := uint256.FromDecimal(whole + frac + strings.Repeat("0", scale-len(frac)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrInvalidAmount, value, err)
+	}
+	return amount, nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/holiman/uint256"
+)
+
+func TestFormatAmount(t *testing.T) {
+	cases := []struct {
+		amount   *uint256.Int
+		decimals uint32
+		want     string
+	}{
+		{uint256.NewInt(1500000), 6, "1.5"},
+		{uint256.NewInt(1), 6, "0.000001"},
+		{uint256.NewInt(2000000), 6, "2"},
+		{uint256.NewInt(0), 6, "0"},
+		{nil, 6, "0"},
+		{uint256.NewInt(1500000), 0, "1.5"},
+		{uint256.NewInt(123), 2, "1.23"},
+	}
+	for _, c := range cases {
+		if got := FormatAmount(c.amount, c.decimals); got != c.want {
+			t.Errorf("FormatAmount(%v, %d) = %q, want %q", c.amount, c.decimals, got, c.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	got, err := ParseAmount("1.5", 6)
+	if err != nil {
+		t.Fatalf("ParseAmount() error = %v", err)
+	}
+	if got.Uint64() != 1500000 {
+		t.Errorf("ParseAmount(1.5) = %s, want 1500000", got)
+	}
+
+	got, err = ParseAmount(".25", 2)
+	if err != nil || got.Uint64() != 25 {
+		t.Errorf("ParseAmount(.25) = %v, %v, want 25", got, err)
+	}
+
+	for _, bad := range []string{"", ".", "1.0000001", "abc", "1.x"} {
+		if _, err := ParseAmount(bad, 6); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ParseAmount(%q) error = %v, want ErrInvalidAmount", bad, err)
+		}
+	}
+}
+
+func TestCheckDecimals(t *testing.T) {
+	if err := CheckDecimals(0); err != nil {
+		t.Errorf("CheckDecimals(0) = %v, want nil", err)
+	}
+	if err := CheckDecimals(NATIVE_DECIMAL); err != nil {
+		t.Errorf("CheckDecimals(NATIVE_DECIMAL) = %v, want nil", err)
+	}
+	if err := (Account{Decimals: 9}).CheckDecimals(); !errors.Is(err, ErrDecimalsMismatch) {
+		t.Errorf("Account.CheckDecimals() = %v, want ErrDecimalsMismatch", err)
+	}
+}
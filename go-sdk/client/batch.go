@@ -0,0 +1,302 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrSenderHalted        = errors.New("batch: an earlier transaction from the sender failed")
+	ErrBatchOutcomeUnknown = errors.New("batch: transaction may have been accepted before a crash, but it cannot be looked up")
+)
+
+// BatchOptions configures AddTxBatch.
+type BatchOptions struct {
+	// Concurrency bounds how many senders are submitted in parallel. Defaults to Config.BatchConcurrency.
+	Concurrency int
+	// RatePerSecond caps AddTx calls across the whole batch. Zero means no limit.
+	RatePerSecond float64
+	// CheckpointPath, when set, records every accepted transaction in this file. A later
+	// run with the same path skips transactions already recorded there.
+	CheckpointPath string
+	// InFlight bounds how many AddTx calls of one sender await an answer at a time.
+	// Defaults to 8; 1 sends each transaction only after the previous one was accepted.
+	InFlight int
+	// TxHash computes the hash the node assigns to a transaction. When set, a transaction
+	// sent but not recorded before a crash is looked up on the rerun. Without it, such a
+	// transaction fails with ErrBatchOutcomeUnknown.
+	TxHash func(tx *Tx) string
+}
+
+const defaultBatchInFlight = 8
+
+// BatchItemResult is the outcome of one transaction of AddTxBatch. Skipped is set when
+// the transaction was found in the checkpoint and not sent again, Recovered when the
+// node rejected it as already used but, looked up with BatchOptions.TxHash, turned out
+// to have this very transaction.
+type BatchItemResult struct {
+	Index     int
+	TxHash    string
+	Err       error
+	Skipped   bool
+	Recovered bool
+}
+
+// AddTxBatch submits txs and returns one result per transaction, in the order of txs.
+//
+// Transactions from the same sender are sent in nonce order with up to InFlight calls
+// pipelined; different senders are sent in parallel. A transaction that overtakes its
+// predecessor and is rejected with ErrNonceTooHigh is sent again once the predecessor is
+// answered. Once a transaction fails, the sender's later transactions are not sent and
+// fail with ErrSenderHalted.
+//
+// With a checkpoint, a crashed job can be rerun on the same input without paying twice.
+// Every transaction is marked in the checkpoint before it is sent. One that was marked
+// but not recorded as accepted when the process died, and that the rerun sees rejected as
+// a used nonce or a duplicate, is looked up with BatchOptions.TxHash; if the node has it,
+// it is recorded and reported as Recovered. Without TxHash it fails with
+// ErrBatchOutcomeUnknown, halting its sender.
+//
+// The returned error is non-nil when the checkpoint cannot be used or ctx ends; results
+// of transactions that were not sent then carry ctx.Err().
+func (c *MmnClient) AddTxBatch(ctx context.Context, txs []SignedTx, opts BatchOptions) ([]BatchItemResult, error) {
+	results := make([]BatchItemResult, len(txs))
+	for i := range results {
+		results[i].Index = i
+	}
+
+	var cp *checkpoint
+	if opts.CheckpointPath != "" {
+		var err error
+		if cp, err = openCheckpoint(opts.CheckpointPath); err != nil {
+			return nil, err
+		}
+		defer cp.Close()
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = c.batchConcurrency()
+	}
+	inFlight := opts.InFlight
+	if inFlight <= 0 {
+		inFlight = defaultBatchInFlight
+	}
+	limiter := newRateLimiter(opts.RatePerSecond)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	queues := senderQueues(txs)
+	for qi, queue := range queues {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			for _, q := range queues[qi:] {
+				for _, i := range q {
+					results[i].Err = ctx.Err()
+				}
+			}
+			return results, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(queue []int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			c.sendQueue(ctx, txs, queue, results, cp, limiter, inFlight, opts.TxHash)
+		}(queue)
+	}
+	wg.Wait()
+
+	return results, ctx.Err()
+}
+
+// sendQueue submits one sender's transactions in order with up to inFlight calls pending.
+func (c *MmnClient) sendQueue(ctx context.Context, txs []SignedTx, queue []int, results []BatchItemResult,
+	cp *checkpoint, limiter *rateLimiter, inFlight int, txHash func(*Tx) string) {
+	var (
+		mu     sync.Mutex
+		halted error
+		wg     sync.WaitGroup
+	)
+	haltedErr := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		return halted
+	}
+	halt := func(err error) {
+		mu.Lock()
+		if halted == nil {
+			halted = err
+		}
+		mu.Unlock()
+	}
+
+	slots := make(chan struct{}, inFlight)
+	// prev is closed once the previous transaction of the queue is answered.
+	prev := make(chan struct{})
+	close(prev)
+	for _, i := range queue {
+		res := &results[i]
+		if cp != nil {
+			if hash, ok := cp.Lookup(txs[i].Tx); ok {
+				res.TxHash, res.Skipped = hash, true
+				continue
+			}
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			res.Err = ctx.Err()
+			halt(ctx.Err())
+			continue
+		}
+		// Checked once a slot is free, so nothing is sent after a rejection answered meanwhile.
+		if err := haltedErr(); err != nil {
+			<-slots
+			res.Err = err
+			continue
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			<-slots
+			res.Err = err
+			halt(err)
+			continue
+		}
+		var maybeSent bool
+		if cp != nil {
+			var err error
+			if maybeSent, err = cp.Intend(txs[i].Tx); err != nil {
+				<-slots
+				res.Err = err
+				halt(fmt.Errorf("%w: transaction %d: %v", ErrSenderHalted, i, err))
+				continue
+			}
+		}
+
+		done := make(chan struct{})
+		wg.Add(1)
+		go func(i int, prev <-chan struct{}) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			defer close(done)
+			err := c.sendBatchItem(ctx, txs[i], res, cp, limiter, prev, haltedErr, maybeSent, txHash)
+			if err != nil {
+				res.Err = err
+				halt(fmt.Errorf("%w: transaction %d: %v", ErrSenderHalted, i, err))
+			}
+		}(i, prev)
+		prev = done
+	}
+	wg.Wait()
+}
+
+// sendBatchItem sends tx and records it in the checkpoint. A rejection for a nonce gap is
+// retried once prev is closed, unless the sender was halted meanwhile. maybeSent is set
+// when an earlier run may have sent tx. It returns the error that halts the sender; an
+// item failed by an earlier halt gets res.Err only.
+func (c *MmnClient) sendBatchItem(ctx context.Context, tx SignedTx, res *BatchItemResult, cp *checkpoint,
+	limiter *rateLimiter, prev <-chan struct{}, haltedErr func() error, maybeSent bool, txHash func(*Tx) string) error {
+	added, err := c.AddTx(ctx, tx)
+	if errors.Is(err, ErrNonceTooHigh) {
+		select {
+		case <-prev:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if h := haltedErr(); h != nil {
+			res.Err = h
+			return nil
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		added, err = c.AddTx(ctx, tx)
+	}
+	hash := added.TxHash
+	if maybeSent && (errors.Is(err, ErrNonceTooLow) || errors.Is(err, ErrDuplicateTx)) {
+		if txHash == nil {
+			// The node may well have it; sending the sender's later nonces could make it pay twice.
+			return fmt.Errorf("%w: %v", ErrBatchOutcomeUnknown, err)
+		}
+		if known := txHash(tx.Tx); c.knownTx(ctx, known) {
+			hash, err, res.Recovered = known, nil, true
+		}
+	}
+	if err != nil {
+		return err
+	}
+	res.TxHash = hash
+	if cp != nil {
+		return cp.Record(tx.Tx, hash)
+	}
+	return nil
+}
+
+// knownTx reports whether the node has the transaction with hash, rather than another
+// transaction with its nonce, and has not failed it.
+func (c *MmnClient) knownTx(ctx context.Context, hash string) bool {
+	info, err := c.GetTxByHash(ctx, hash)
+	return err == nil && TxMeta_Status(info.Status) != TxMeta_Status_FAILED
+}
+
+// senderQueues groups the indexes of txs by sender, each group sorted by nonce.
+func senderQueues(txs []SignedTx) [][]int {
+	bySender := make(map[string]int)
+	var queues [][]int
+	for i, tx := range txs {
+		qi, ok := bySender[tx.Tx.Sender]
+		if !ok {
+			qi = len(queues)
+			bySender[tx.Tx.Sender] = qi
+			queues = append(queues, nil)
+		}
+		queues[qi] = append(queues[qi], i)
+	}
+	for _, q := range queues {
+		sort.SliceStable(q, func(a, b int) bool { return txs[q[a]].Tx.Nonce < txs[q[b]].Tx.Nonce })
+	}
+	return queues
+}
+
+// rateLimiter spaces calls evenly at a fixed rate. A nil *rateLimiter does not limit.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	t := time.NewTimer(time.Until(at))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
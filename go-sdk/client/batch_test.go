@@ -0,0 +1,228 @@
+package client_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mezonai/mmn-sdk/go-sdk/mmntest"
+	"github.com/mr-tron/base58"
+)
+
+type batchSender struct {
+	addr string
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newBatchSender() batchSender {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	return batchSender{addr: base58.Encode(pub), pub: pub, priv: priv}
+}
+
+func (s batchSender) transfers(t *testing.T, to string, nonces ...uint64) []client.SignedTx {
+	t.Helper()
+	var out []client.SignedTx
+	for _, nonce := range nonces {
+		tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, s.addr, to, uint256.NewInt(1), nonce, 0, "", nil, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := client.SignTx(tx, s.pub, s.priv.Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, signed)
+	}
+	return out
+}
+
+func TestClient_AddTxBatch(t *testing.T) {
+	a, b, to := newBatchSender(), newBatchSender(), newBatchSender()
+	node := mmntest.NewNode(
+		mmntest.WithAccount(a.addr, uint256.NewInt(100), 0),
+		mmntest.WithAccount(b.addr, uint256.NewInt(100), 0),
+	)
+	defer node.Close()
+	c, err := node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Out of nonce order and interleaved across senders.
+	txs := append(a.transfers(t, to.addr, 3, 1), b.transfers(t, to.addr, 2, 1)...)
+	txs = append(txs, a.transfers(t, to.addr, 2)...)
+
+	start := time.Now()
+	results, err := c.AddTxBatch(context.Background(), txs, client.BatchOptions{Concurrency: 2, RatePerSecond: 200})
+	if err != nil {
+		t.Fatalf("AddTxBatch() error = %v", err)
+	}
+	for _, r := range results {
+		if r.Err != nil || r.TxHash == "" {
+			t.Errorf("result %d = %+v", r.Index, r)
+		}
+	}
+	if node.PendingCount() != len(txs) {
+		t.Errorf("PendingCount() = %d, want %d", node.PendingCount(), len(txs))
+	}
+	if elapsed := time.Since(start); elapsed < 4*5*time.Millisecond {
+		t.Errorf("5 transactions at 200/s took %v", elapsed)
+	}
+}
+
+func TestClient_AddTxBatch_Resume(t *testing.T) {
+	a, to := newBatchSender(), newBatchSender()
+	s := mmntest.NewScenario()
+	s.On("AddTx").After(2).Times(1).Unavailable()
+	node := mmntest.NewNode(mmntest.WithAccount(a.addr, uint256.NewInt(100), 0), mmntest.WithScenario(s))
+	defer node.Close()
+	c, err := node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	txs := a.transfers(t, to.addr, 1, 2, 3, 4)
+	checkpoint := filepath.Join(t.TempDir(), "airdrop.ckpt")
+	// One call at a time, so that the fault hits the third transaction.
+	opts := client.BatchOptions{CheckpointPath: checkpoint, InFlight: 1}
+
+	results, err := c.AddTxBatch(context.Background(), txs, opts)
+	if err != nil {
+		t.Fatalf("AddTxBatch() error = %v", err)
+	}
+	if results[1].Err != nil || !client.IsRetryable(results[2].Err) || !errors.Is(results[3].Err, client.ErrSenderHalted) {
+		t.Fatalf("first run results = %+v", results)
+	}
+
+	// Simulate a crash that left half a line behind.
+	f, _ := os.OpenFile(checkpoint, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"id":"trunc`)
+	f.Close()
+
+	results, err = c.AddTxBatch(context.Background(), txs, opts)
+	if err != nil {
+		t.Fatalf("resumed AddTxBatch() error = %v", err)
+	}
+	for i, r := range results {
+		if r.Err != nil || r.Skipped != (i < 2) {
+			t.Errorf("resumed result %d = %+v", i, r)
+		}
+	}
+	if got := s.Calls("AddTx"); got != 5 {
+		t.Errorf("AddTx calls = %d, want 5", got)
+	}
+	if node.PendingCount() != 4 {
+		t.Errorf("PendingCount() = %d, want 4", node.PendingCount())
+	}
+}
+
+func TestClient_AddTxBatch_CrashBeforeRecord(t *testing.T) {
+	a, to := newBatchSender(), newBatchSender()
+	node := mmntest.NewNode(mmntest.WithAccount(a.addr, uint256.NewInt(100), 0))
+	defer node.Close()
+	c, err := node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	txs := a.transfers(t, to.addr, 1, 2, 3, 4)
+	path := filepath.Join(t.TempDir(), "airdrop.ckpt")
+	if _, err := c.AddTxBatch(context.Background(), txs[:3], client.BatchOptions{CheckpointPath: path, InFlight: 1}); err != nil {
+		t.Fatal(err)
+	}
+	// The process died after the node accepted the third transaction but before it was recorded.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+	if err := os.WriteFile(path, []byte(strings.Join(lines[:len(lines)-1], "")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without TxHash the third transaction cannot be told apart from another one with its nonce.
+	results, err := c.AddTxBatch(context.Background(), txs, client.BatchOptions{CheckpointPath: path, InFlight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(results[2].Err, client.ErrBatchOutcomeUnknown) || !errors.Is(results[3].Err, client.ErrSenderHalted) {
+		t.Errorf("results without TxHash = %+v", results)
+	}
+
+	opts := client.BatchOptions{CheckpointPath: path, TxHash: mmntest.TxHash}
+	for run := range 2 {
+		results, err := c.AddTxBatch(context.Background(), txs, opts)
+		if err != nil {
+			t.Fatalf("run %d: AddTxBatch() error = %v", run, err)
+		}
+		for i, r := range results {
+			recovered := run == 0 && i == 2
+			if r.Err != nil || r.TxHash == "" || r.Recovered != recovered || r.Skipped != (i < 2 || run == 1) {
+				t.Errorf("run %d: result %d = %+v", run, i, r)
+			}
+		}
+	}
+	if node.PendingCount() != 4 {
+		t.Errorf("PendingCount() = %d, want 4", node.PendingCount())
+	}
+}
+
+func TestClient_AddTxBatch_Pipelined(t *testing.T) {
+	a, to := newBatchSender(), newBatchSender()
+	s := mmntest.NewScenario()
+	s.On("AddTx").Times(10).Latency(40 * time.Millisecond)
+	node := mmntest.NewNode(mmntest.WithAccount(a.addr, uint256.NewInt(100), 0), mmntest.WithScenario(s))
+	defer node.Close()
+	c, err := node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	txs := a.transfers(t, to.addr, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	start := time.Now()
+	results, err := c.AddTxBatch(context.Background(), txs, client.BatchOptions{InFlight: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("result %d = %+v", r.Index, r)
+		}
+	}
+	// Sent one at a time, ten calls take at least 400ms.
+	if elapsed := time.Since(start); elapsed >= 10*40*time.Millisecond {
+		t.Errorf("pipelined batch took %v", elapsed)
+	}
+
+	// A real rejection still halts the sender, even with later calls in flight: whichever
+	// call the node rejects, the transactions before it are accepted and those after it
+	// are not sent or wait for it and fail with ErrSenderHalted.
+	s.On("AddTx").Times(1).Reject("insufficient balance")
+	results, _ = c.AddTxBatch(context.Background(), a.transfers(t, to.addr, 11, 12, 13), client.BatchOptions{InFlight: 3})
+	rejected := -1
+	for i, r := range results {
+		switch {
+		case rejected < 0 && errors.Is(r.Err, client.ErrInsufficientBalance):
+			rejected = i
+		case rejected < 0 && r.Err == nil:
+		case rejected >= 0 && errors.Is(r.Err, client.ErrSenderHalted):
+		default:
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+	if rejected < 0 || node.PendingCount() != 10+rejected {
+		t.Errorf("rejected index %d, %d pending", rejected, node.PendingCount())
+	}
+}
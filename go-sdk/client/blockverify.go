@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"github.com/mr-tron/base58"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrBlockPrevHash    = errors.New("block: prev_hash does not link to the previous block")
+	ErrBlockEntryHash   = errors.New("block: entry hash mismatch")
+	ErrBlockTxHashes    = errors.New("block: tx_hashes do not match the transactions")
+	ErrBlockTxData      = errors.New("block: transaction_data does not match the transactions")
+	ErrBlockTxSignature = errors.New("block: invalid transaction signature")
+	ErrBlockHash        = errors.New("block: hash mismatch")
+	ErrBlockLeader      = errors.New("block: invalid leader id")
+	ErrBlockSignature   = errors.New("block: invalid leader signature")
+)
+
+// BlockScheme is how a network hashes entries, blocks and transactions and checks
+// transaction signatures. The client has no default: no production node's hashing is
+// part of this repository, so callers supply the scheme of their network, checked
+// against its blocks. mmntest.BlockScheme is the scheme of the fake node. All fields
+// are required.
+type BlockScheme struct {
+	// EntryHash returns the hash of e following the hash prev.
+	EntryHash func(prev []byte, e Entry) ([]byte, error)
+	// BlockHash returns the hash the leader signs.
+	BlockHash func(slot uint64, prevHash, lastEntryHash []byte) []byte
+	// TxHash returns the hash an entry lists in tx_hashes for tx.
+	TxHash func(tx *Tx) string
+	// VerifyTx checks the signature of a transaction in an entry.
+	VerifyTx func(tx *Tx, sig string) bool
+}
+
+// VerifyBlock checks that b is internally consistent and signed by its leader:
+//
+//   - Each entry's hash is s.EntryHash of the previous one, starting at PrevHash.
+//   - Each entry's transactions pass s.VerifyTx and their s.TxHash are the entry's
+//     tx_hashes. Together they match the block's transaction data in order, field by
+//     field.
+//   - Hash is s.BlockHash of the slot, PrevHash and the last entry hash.
+//   - Signature is the Ed25519 signature of Hash by the key LeaderID encodes.
+//
+// LeaderID is taken from the block itself; callers that know the leader schedule
+// should also compare it against the expected leader.
+func (s BlockScheme) VerifyBlock(b *Block) error {
+	entryHash := b.PrevHash
+	var txs []SignedTx
+	var txHashes []string
+	for i, e := range b.Entries {
+		decoded, err := s.verifyEntryTxs(e)
+		if err != nil {
+			return fmt.Errorf("slot %d entry %d: %w", b.Slot, i, err)
+		}
+		h, err := s.EntryHash(entryHash, e)
+		if err != nil {
+			return fmt.Errorf("slot %d entry %d: %w", b.Slot, i, err)
+		}
+		if !bytes.Equal(h, e.Hash) {
+			return fmt.Errorf("%w: slot %d entry %d", ErrBlockEntryHash, b.Slot, i)
+		}
+		entryHash = h
+		txs = append(txs, decoded...)
+		txHashes = append(txHashes, e.TxHashes...)
+	}
+
+	if len(txHashes) != len(b.Transactions) {
+		return fmt.Errorf("%w: slot %d has %d entry transactions and %d in transaction_data",
+			ErrBlockTxHashes, b.Slot, len(txHashes), len(b.Transactions))
+	}
+	for i, td := range b.Transactions {
+		if td.TxHash != txHashes[i] {
+			return fmt.Errorf("%w: slot %d transaction_data[%d] is %s, entries list %s",
+				ErrBlockTxHashes, b.Slot, i, td.TxHash, txHashes[i])
+		}
+		if field := mismatchedField(td, txs[i].Tx); field != "" {
+			return fmt.Errorf("%w: slot %d transaction_data[%d] %s", ErrBlockTxData, b.Slot, i, field)
+		}
+	}
+
+	if !bytes.Equal(s.BlockHash(b.Slot, b.PrevHash, entryHash), b.Hash) {
+		return fmt.Errorf("%w: slot %d", ErrBlockHash, b.Slot)
+	}
+
+	leader, err := base58.Decode(b.LeaderID)
+	if err != nil || len(leader) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: slot %d: %q", ErrBlockLeader, b.Slot, b.LeaderID)
+	}
+	if !ed25519.Verify(leader, b.Hash, b.Signature) {
+		return fmt.Errorf("%w: slot %d", ErrBlockSignature, b.Slot)
+	}
+	return nil
+}
+
+// VerifyBlocks verifies each block with s.VerifyBlock and checks that each PrevHash is
+// the hash of the block before it. blocks must be in slot order with no produced block
+// left out. A non-nil prevHash, such as the hash of an already trusted block, is
+// checked against the first block's PrevHash.
+func (s BlockScheme) VerifyBlocks(prevHash []byte, blocks ...Block) error {
+	for i := range blocks {
+		b := &blocks[i]
+		if i > 0 {
+			if b.Slot <= blocks[i-1].Slot {
+				return fmt.Errorf("%w: slot %d follows slot %d", ErrBlockPrevHash, b.Slot, blocks[i-1].Slot)
+			}
+			prevHash = blocks[i-1].Hash
+		}
+		if prevHash != nil && !bytes.Equal(b.PrevHash, prevHash) {
+			return fmt.Errorf("%w: slot %d", ErrBlockPrevHash, b.Slot)
+		}
+		if err := s.VerifyBlock(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyEntryTxs decodes e.Transactions and checks their signatures and that
+// e.TxHashes are their hashes.
+func (s BlockScheme) verifyEntryTxs(e Entry) ([]SignedTx, error) {
+	if len(e.Transactions) != len(e.TxHashes) {
+		return nil, fmt.Errorf("%w: %d transactions and %d tx_hashes", ErrBlockTxHashes, len(e.Transactions), len(e.TxHashes))
+	}
+	txs := make([]SignedTx, 0, len(e.Transactions))
+	for i, raw := range e.Transactions {
+		var msg mmnpb.SignedTxMsg
+		if err := proto.Unmarshal(raw, &msg); err != nil {
+			return nil, fmt.Errorf("%w: transactions[%d]: %v", ErrBlockTxHashes, i, err)
+		}
+		signed, err := FromProtoSigTx(&msg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: transactions[%d]: %v", ErrBlockTxHashes, i, err)
+		}
+		if id := s.TxHash(signed.Tx); id != e.TxHashes[i] {
+			return nil, fmt.Errorf("%w: transactions[%d] hashes to %s, tx_hashes lists %s", ErrBlockTxHashes, i, id, e.TxHashes[i])
+		}
+		if !s.VerifyTx(signed.Tx, signed.Sig) {
+			return nil, fmt.Errorf("%w: transactions[%d] %s", ErrBlockTxSignature, i, e.TxHashes[i])
+		}
+		txs = append(txs, signed)
+	}
+	return txs, nil
+}
+
+// mismatchedField returns the name of the first field of td that differs from tx, or "".
+func mismatchedField(td TransactionData, tx *Tx) string {
+	switch {
+	case td.Sender != tx.Sender:
+		return "sender"
+	case td.Recipient != tx.Recipient:
+		return "recipient"
+	case !amountEqual(td.Amount, tx.Amount):
+		return "amount"
+	case td.Nonce != tx.Nonce:
+		return "nonce"
+	case td.TextData != tx.TextData:
+		return "text_data"
+	case td.ExtraInfo != tx.ExtraInfo:
+		return "extra_info"
+	}
+	return ""
+}
+
+func amountEqual(a, b *uint256.Int) bool {
+	if a == nil || b == nil {
+		return (a == nil || a.IsZero()) && (b == nil || b.IsZero())
+	}
+	return a.Eq(b)
+}
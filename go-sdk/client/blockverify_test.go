@@ -0,0 +1,116 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mezonai/mmn-sdk/go-sdk/mmntest"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"github.com/mr-tron/base58"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestVerifyBlocks(t *testing.T) {
+	a, to := newBatchSender(), newBatchSender()
+	node := mmntest.NewNode(mmntest.WithAccount(a.addr, uint256.NewInt(100), 0))
+	defer node.Close()
+	c, err := node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	if _, err := c.AddTxBatch(ctx, a.transfers(t, to.addr, 1, 2), client.BatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	node.ProduceBlock()
+	node.ProduceBlock()
+	blocks, err := c.GetBlockByNumber(ctx, 0, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks[1].Entries) != 2 {
+		t.Fatalf("slot 1 has %d entries, want 2", len(blocks[1].Entries))
+	}
+	if err := mmntest.BlockScheme.VerifyBlocks(blocks[0].Hash, blocks[1:]...); err != nil {
+		t.Fatalf("VerifyBlocks() error = %v", err)
+	}
+
+	tamper := func(f func(b []client.Block)) []client.Block {
+		bs, _ := c.GetBlockByNumber(ctx, 0, 1, 2)
+		f(bs)
+		return bs
+	}
+	// forge re-signs the first transaction of slot 1 with another key. The signature is
+	// not hashed into the tx hash, so only the signature check catches it.
+	forge := func(b []client.Block) {
+		var msg mmnpb.SignedTxMsg
+		if err := proto.Unmarshal(b[1].Entries[0].Transactions[0], &msg); err != nil {
+			t.Fatal(err)
+		}
+		msg.Signature = base58.Encode(ed25519.Sign(to.priv, []byte("forged")))
+		b[1].Entries[0].Transactions[0], _ = proto.Marshal(&msg)
+	}
+	for name, tc := range map[string]struct {
+		blocks []client.Block
+		want   error
+	}{
+		"entry hash": {tamper(func(b []client.Block) { b[1].Entries[1].Hash[0] ^= 1 }), client.ErrBlockEntryHash},
+		"tx hashes": {tamper(func(b []client.Block) {
+			b[1].Entries[0].TxHashes, b[1].Entries[1].TxHashes = b[1].Entries[1].TxHashes, b[1].Entries[0].TxHashes
+		}), client.ErrBlockTxHashes},
+		"transaction data":      {tamper(func(b []client.Block) { b[1].Transactions = b[1].Transactions[:1] }), client.ErrBlockTxHashes},
+		"transaction field":     {tamper(func(b []client.Block) { b[1].Transactions[1].Amount = uint256.NewInt(99) }), client.ErrBlockTxData},
+		"transaction signature": {tamper(forge), client.ErrBlockTxSignature},
+		"slot":                  {tamper(func(b []client.Block) { b[1].Slot = 5 }), client.ErrBlockHash},
+		"signature":             {tamper(func(b []client.Block) { b[2].Signature[0] ^= 1 }), client.ErrBlockSignature},
+		"leader":                {tamper(func(b []client.Block) { b[2].LeaderID = to.addr }), client.ErrBlockSignature},
+		"prev link":             {tamper(func(b []client.Block) { b[1] = b[2] }), client.ErrBlockPrevHash},
+	} {
+		if err := mmntest.BlockScheme.VerifyBlocks(nil, tc.blocks...); !errors.Is(err, tc.want) {
+			t.Errorf("%s: VerifyBlocks() error = %v, want %v", name, err, tc.want)
+		}
+	}
+	if err := mmntest.BlockScheme.VerifyBlocks(bytes.Repeat([]byte{1}, 32), blocks...); !errors.Is(err, client.ErrBlockPrevHash) {
+		t.Errorf("VerifyBlocks() from another trusted hash error = %v", err)
+	}
+
+	// A scheme with another signature check accepts what the fake node's rejects.
+	lax := mmntest.BlockScheme
+	lax.VerifyTx = func(*client.Tx, string) bool { return true }
+	if err := lax.VerifyBlocks(nil, tamper(forge)...); err != nil {
+		t.Errorf("VerifyBlocks() with a lax scheme error = %v", err)
+	}
+}
+
+func TestVerifyBlock_TickEntries(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	prev := make([]byte, sha256.Size)
+	h := prev
+	for range 3 {
+		sum := sha256.Sum256(h)
+		h = sum[:]
+	}
+	b := client.Block{
+		Slot:     7,
+		PrevHash: prev,
+		Entries:  []client.Entry{{NumHashes: 3, Hash: h}},
+		LeaderID: base58.Encode(pub),
+		Hash:     mmntest.BlockScheme.BlockHash(7, prev, h),
+	}
+	b.Signature = ed25519.Sign(priv, b.Hash)
+	if err := mmntest.BlockScheme.VerifyBlock(&b); err != nil {
+		t.Fatalf("VerifyBlock() error = %v", err)
+	}
+	b.Entries[0].NumHashes = 2
+	if err := mmntest.BlockScheme.VerifyBlock(&b); !errors.Is(err, client.ErrBlockEntryHash) {
+		t.Errorf("VerifyBlock() with fewer hashes error = %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mr-tron/base58"
+)
+
+// BundleVersion is the version of the offline signing bundle format.
+const BundleVersion = 1
+
+var (
+	ErrInvalidBundle         = errors.New("bundle: invalid bundle")
+	ErrBundleVersion         = errors.New("bundle: unsupported version")
+	ErrBundlePayloadMismatch = errors.New("bundle: payload does not match transaction")
+	ErrBundleAlreadySigned   = errors.New("bundle: already signed")
+	ErrBundleNotSigned       = errors.New("bundle: not signed")
+)
+
+// BundleKind tells an unsigned bundle from a signed one.
+type BundleKind string
+
+const (
+	BundleUnsigned BundleKind = "unsigned_tx"
+	BundleSigned   BundleKind = "signed_tx"
+)
+
+// Bundle carries a transaction between an online machine and an offline signer.
+// Payload is Serialize(Tx), the exact bytes that get signed, so the signer can display
+// and check them; TxID is its hash. Sig is set once the bundle is signed.
+type Bundle struct {
+	Version int        `json:"version"`
+	Kind    BundleKind `json:"kind"`
+	Tx      *Tx        `json:"tx"`
+	Payload string     `json:"payload"`
+	TxID    string     `json:"tx_id"`
+	Sig     string     `json:"sig,omitempty"`
+}
+
+// NewUnsignedBundle wraps tx for export to an offline signer.
+func NewUnsignedBundle(tx *Tx) Bundle {
+	return Bundle{
+		Version: BundleVersion,
+		Kind:    BundleUnsigned,
+		Tx:      tx,
+		Payload: string(Serialize(tx)),
+		TxID:    TxID(tx),
+	}
+}
+
+// NewSignedBundle wraps an already signed transaction.
+func NewSignedBundle(tx SignedTx) Bundle {
+	b := NewUnsignedBundle(tx.Tx)
+	b.Kind, b.Sig = BundleSigned, tx.Sig
+	return b
+}
+
+// Sign signs an unsigned bundle with SignTx and returns the signed bundle.
+func (b Bundle) Sign(pubKey, privKey []byte) (Bundle, error) {
+	if b.Kind != BundleUnsigned {
+		return Bundle{}, ErrBundleAlreadySigned
+	}
+	if err := b.check(); err != nil {
+		return Bundle{}, err
+	}
+	signed, err := SignTx(b.Tx, pubKey, privKey)
+	if err != nil {
+		return Bundle{}, err
+	}
+	return NewSignedBundle(signed), nil
+}
+
+// SignedTx returns the transaction of a signed bundle after checking its signature with Verify.
+func (b Bundle) SignedTx() (SignedTx, error) {
+	if b.Kind != BundleSigned {
+		return SignedTx{}, ErrBundleNotSigned
+	}
+	if err := b.check(); err != nil {
+		return SignedTx{}, err
+	}
+	if !Verify(b.Tx, b.Sig) {
+		return SignedTx{}, ErrInvalidSignature
+	}
+	return SignedTx{Tx: b.Tx, Sig: b.Sig}, nil
+}
+
+// check makes sure the displayed payload is what would be signed.
+func (b Bundle) check() error {
+	if b.Tx == nil {
+		return fmt.Errorf("%w: missing tx", ErrInvalidBundle)
+	}
+	if string(Serialize(b.Tx)) != b.Payload || TxID(b.Tx) != b.TxID {
+		return ErrBundlePayloadMismatch
+	}
+	return nil
+}
+
+// MarshalBundle encodes b as indented JSON.
+func MarshalBundle(b Bundle) ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
+
+// EncodeBundleBase58 encodes b as base58 of its compact JSON, for QR codes and copy-paste.
+func EncodeBundleBase58(b Bundle) (string, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return "", err
+	}
+	return base58.Encode(data), nil
+}
+
+// ParseBundle decodes a bundle from JSON or base58 and checks its version, kind and payload.
+func ParseBundle(data []byte) (Bundle, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' {
+		decoded, err := base58.Decode(string(data))
+		if err != nil {
+			return Bundle{}, fmt.Errorf("%w: neither JSON nor base58", ErrInvalidBundle)
+		}
+		data = decoded
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Bundle{}, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if b.Version != BundleVersion {
+		return Bundle{}, fmt.Errorf("%w: %d", ErrBundleVersion, b.Version)
+	}
+	switch b.Kind {
+	case BundleUnsigned:
+		if b.Sig != "" {
+			return Bundle{}, fmt.Errorf("%w: unsigned bundle has a signature", ErrInvalidBundle)
+		}
+	case BundleSigned:
+		if b.Sig == "" {
+			return Bundle{}, fmt.Errorf("%w: signed bundle has no signature", ErrInvalidBundle)
+		}
+	default:
+		return Bundle{}, fmt.Errorf("%w: kind %q", ErrInvalidBundle, b.Kind)
+	}
+	if err := b.check(); err != nil {
+		return Bundle{}, err
+	}
+	return b, nil
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/mr-tron/base58"
+)
+
+func TestBundle_RoundTrip(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	tx, err := BuildTransferTx(TxTypeTransferByKey, base58.Encode(pub), GenerateAddress("cold"), uint256.NewInt(7), 3, 0, "", nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := MarshalBundle(NewUnsignedBundle(tx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := ParseBundle(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unsigned.SignedTx(); !errors.Is(err, ErrBundleNotSigned) {
+		t.Errorf("SignedTx() of unsigned bundle error = %v", err)
+	}
+
+	signed, err := unsigned.Sign(pub, priv.Seed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := EncodeBundleBase58(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseBundle([]byte(encoded + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stx, err := parsed.SignedTx()
+	if err != nil || stx.Tx.Nonce != 3 || !Verify(stx.Tx, stx.Sig) {
+		t.Fatalf("SignedTx() = %+v, %v", stx, err)
+	}
+	if _, err := parsed.Sign(pub, priv.Seed()); !errors.Is(err, ErrBundleAlreadySigned) {
+		t.Errorf("Sign() of signed bundle error = %v", err)
+	}
+}
+
+func TestParseBundle_Rejects(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	tx, _ := BuildTransferTx(TxTypeTransferByKey, base58.Encode(pub), GenerateAddress("cold"), uint256.NewInt(7), 1, 0, "", nil, "", "")
+	data, _ := MarshalBundle(NewUnsignedBundle(tx))
+
+	tampered := strings.Replace(string(data), `"amount": "7"`, `"amount": "700"`, 1)
+	if _, err := ParseBundle([]byte(tampered)); !errors.Is(err, ErrBundlePayloadMismatch) {
+		t.Errorf("ParseBundle() of changed amount error = %v", err)
+	}
+	future := strings.Replace(string(data), `"version": 1`, `"version": 2`, 1)
+	if _, err := ParseBundle([]byte(future)); !errors.Is(err, ErrBundleVersion) {
+		t.Errorf("ParseBundle() of future version error = %v", err)
+	}
+	if _, err := ParseBundle([]byte("0OIl")); !errors.Is(err, ErrInvalidBundle) {
+		t.Errorf("ParseBundle() of garbage error = %v", err)
+	}
+
+	forged := NewSignedBundle(SignedTx{Tx: tx, Sig: base58.Encode(make([]byte, ed25519.SignatureSize))})
+	if _, err := forged.SignedTx(); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("SignedTx() of bad signature error = %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// checkpoint is an append-only file of transactions, one JSON object per line: an intent
+// line before a transaction is sent and a line with its hash once it was accepted. Lines
+// that do not parse, such as one cut short by a crash, are ignored.
+type checkpoint struct {
+	mu      sync.Mutex
+	f       *os.File
+	done    map[string]string
+	intents map[string]bool
+}
+
+type checkpointEntry struct {
+	ID     string `json:"id"`
+	TxHash string `json:"tx_hash,omitempty"`
+	Intent bool   `json:"intent,omitempty"`
+}
+
+func openCheckpoint(path string) (*checkpoint, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("batch: open checkpoint: %w", err)
+	}
+
+	cp := &checkpoint{f: f, done: make(map[string]string), intents: make(map[string]bool)}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e checkpointEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil || e.ID == "" {
+			continue
+		}
+		if e.Intent {
+			cp.intents[e.ID] = true
+		} else {
+			cp.done[e.ID] = e.TxHash
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("batch: read checkpoint: %w", err)
+	}
+	if err := terminateLastLine(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("batch: repair checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// terminateLastLine ends a line left unterminated by a crash, so the next record starts on its own line.
+func terminateLastLine(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	_, err = f.Write([]byte{'\n'})
+	return err
+}
+
+func (cp *checkpoint) Lookup(tx *Tx) (string, bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	hash, ok := cp.done[TxID(tx)]
+	return hash, ok
+}
+
+// Intend records that tx is about to be sent. It reports whether an earlier run had
+// already recorded that, meaning the node may have tx although it was never recorded
+// as accepted.
+func (cp *checkpoint) Intend(tx *Tx) (bool, error) {
+	id := TxID(tx)
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if cp.intents[id] {
+		return true, nil
+	}
+	if err := cp.append(checkpointEntry{ID: id, Intent: true}); err != nil {
+		return false, err
+	}
+	cp.intents[id] = true
+	return false, nil
+}
+
+// Record appends tx and syncs the file before returning.
+func (cp *checkpoint) Record(tx *Tx, txHash string) error {
+	id := TxID(tx)
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if err := cp.append(checkpointEntry{ID: id, TxHash: txHash}); err != nil {
+		return err
+	}
+	cp.done[id] = txHash
+	return nil
+}
+
+// append writes e as a line and syncs the file. cp.mu must be held.
+func (cp *checkpoint) append(e checkpointEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := cp.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("batch: write checkpoint: %w", err)
+	}
+	if err := cp.f.Sync(); err != nil {
+		return fmt.Errorf("batch: sync checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (cp *checkpoint) Close() error {
+	return cp.f.Close()
+}
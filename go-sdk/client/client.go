@@ -3,8 +3,8 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync"
 
-	"github.com/holiman/uint256"
 	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
 
 	"google.golang.org/grpc"
@@ -12,9 +12,17 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+const defaultBatchConcurrency = 8
+
 type Config struct {
 	Endpoint string
 	UseTLS   bool
+	// BatchConcurrency bounds in-flight requests of batch calls such as GetAccounts. Defaults to 8.
+	BatchConcurrency int
+	// ZkVerifier, when set, makes AddTx check ZK transfer proofs before broadcasting.
+	ZkVerifier ZkProofVerifier
+	// DialOptions are appended to the options NewClient passes to grpc.NewClient.
+	DialOptions []grpc.DialOption
 }
 
 type MmnClient struct {
@@ -23,6 +31,7 @@ type MmnClient struct {
 	healthClient mmnpb.HealthServiceClient
 	txClient     mmnpb.TxServiceClient
 	accClient    mmnpb.AccountServiceClient
+	blockClient  mmnpb.BlockServiceClient
 }
 
 func NewClient(cfg Config) (*MmnClient, error) {
@@ -34,10 +43,8 @@ func NewClient(cfg Config) (*MmnClient, error) {
 		creds = insecure.NewCredentials()
 	}
 
-	conn, err := grpc.NewClient(
-		cfg.Endpoint,
-		grpc.WithTransportCredentials(creds),
-	)
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, cfg.DialOptions...)
+	conn, err := grpc.NewClient(cfg.Endpoint, opts...)
 
 	if err != nil {
 		return nil, err
@@ -49,26 +56,33 @@ func NewClient(cfg Config) (*MmnClient, error) {
 		healthClient: mmnpb.NewHealthServiceClient(conn),
 		txClient:     mmnpb.NewTxServiceClient(conn),
 		accClient:    mmnpb.NewAccountServiceClient(conn),
+		blockClient:  mmnpb.NewBlockServiceClient(conn),
 	}, nil
 }
 
 func (c *MmnClient) CheckHealth(ctx context.Context) (*mmnpb.HealthCheckResponse, error) {
 	health, err := c.healthClient.Check(ctx, &mmnpb.Empty{})
 	if err != nil {
-		return nil, err
+		return nil, FromRPCError("check-health", err)
 	}
 
 	return health, nil
 }
 
 func (c *MmnClient) AddTx(ctx context.Context, tx SignedTx) (AddTxResponse, error) {
+	if c.cfg.ZkVerifier != nil {
+		if err := VerifySignedZkProof(c.cfg.ZkVerifier, tx); err != nil {
+			return AddTxResponse{}, err
+		}
+	}
+
 	txMsg := ToProtoSigTx(&tx)
 	res, err := c.txClient.AddTx(ctx, txMsg)
 	if err != nil {
-		return AddTxResponse{}, err
+		return AddTxResponse{}, FromRPCError("add-tx", err)
 	}
 	if !res.Ok {
-		return AddTxResponse{}, fmt.Errorf("add-tx failed: %s", res.Error)
+		return AddTxResponse{}, &NodeError{Op: "add-tx", Message: res.Error, Kind: matchNodeError(res.Error)}
 	}
 
 	return AddTxResponse{
@@ -81,17 +95,53 @@ func (c *MmnClient) AddTx(ctx context.Context, tx SignedTx) (AddTxResponse, erro
 func (c *MmnClient) GetAccount(ctx context.Context, addr string) (Account, error) {
 	res, err := c.accClient.GetAccount(ctx, &mmnpb.GetAccountRequest{Address: addr})
 	if err != nil {
-		return Account{Address: addr, Balance: uint256.NewInt(0), Nonce: 0}, err
+		return Account{}, fromRPCErrorNotFound("get-account", err, ErrAccountNotFound)
+	}
+
+	return FromProtoAccount(res)
+}
+
+// GetAccounts looks up addrs with at most Config.BatchConcurrency requests in flight.
+// Results are in the order of addrs; a failed lookup is reported in its AccountResult.
+// The returned error is non-nil only when ctx ends before every lookup has started.
+func (c *MmnClient) GetAccounts(ctx context.Context, addrs ...string) ([]AccountResult, error) {
+	results := make([]AccountResult, len(addrs))
+	sem := make(chan struct{}, c.batchConcurrency())
+	var wg sync.WaitGroup
+
+	for i, addr := range addrs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return results, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int, addr string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			acc, err := c.GetAccount(ctx, addr)
+			results[i] = AccountResult{Address: addr, Account: acc, Err: err}
+		}(i, addr)
 	}
+	wg.Wait()
+
+	return results, nil
+}
 
-	return FromProtoAccount(res), nil
+func (c *MmnClient) batchConcurrency() int {
+	if c.cfg.BatchConcurrency > 0 {
+		return c.cfg.BatchConcurrency
+	}
+	return defaultBatchConcurrency
 }
 
 func (c *MmnClient) SubscribeTransactionStatus(ctx context.Context) (mmnpb.TxService_SubscribeTransactionStatusClient, error) {
 	stream, err := c.txClient.SubscribeTransactionStatus(ctx, &mmnpb.SubscribeTransactionStatusRequest{})
 	if err != nil {
 		fmt.Printf("SubscribeTransactionStatus error: %v", err)
-		return nil, err
+		return nil, FromRPCError("subscribe-transaction-status", err)
 	}
 
 	return stream, nil
@@ -100,37 +150,101 @@ func (c *MmnClient) SubscribeTransactionStatus(ctx context.Context) (mmnpb.TxSer
 func (c *MmnClient) GetTxByHash(ctx context.Context, txHash string) (TxInfo, error) {
 	res, err := c.txClient.GetTxByHash(ctx, &mmnpb.GetTxByHashRequest{TxHash: txHash})
 	if err != nil {
-		return TxInfo{}, err
+		return TxInfo{}, fromRPCErrorNotFound("get-tx-by-hash", err, ErrTxNotFound)
 	}
 	if res.Error != "" {
-		return TxInfo{}, fmt.Errorf("get-tx-by-hash failed: %s", res.Error)
-	}
-
-	return TxInfo{
-		Sender:    res.Tx.Sender,
-		Recipient: res.Tx.Recipient,
-		Amount:    Uint256FromString(res.Tx.Amount),
-		Timestamp: res.Tx.Timestamp,
-		TextData:  res.Tx.TextData,
-		Nonce:     res.Tx.Nonce,
-		Slot:      res.Tx.Slot,
-		Blockhash: res.Tx.Blockhash,
-		Status:    int32(res.Tx.Status),
-		ErrMsg:    res.Tx.ErrMsg,
-		ExtraInfo: res.Tx.ExtraInfo,
-		TxHash:    res.Tx.TxHash,
-	}, nil
+		return TxInfo{}, ParseNodeError("get-tx-by-hash", res.Error)
+	}
+	if res.Tx == nil {
+		return TxInfo{}, &NodeError{Op: "get-tx-by-hash", Message: "empty response for " + txHash, Kind: ErrTxNotFound}
+	}
+
+	return FromProtoTxInfo(res.Tx, res.Decimals)
+}
+
+func (c *MmnClient) GetPendingTransactions(ctx context.Context) (PendingTransactions, error) {
+	res, err := c.txClient.GetPendingTransactions(ctx, &mmnpb.GetPendingTransactionsRequest{})
+	if err != nil {
+		return PendingTransactions{}, FromRPCError("get-pending-transactions", err)
+	}
+	if res.Error != "" {
+		return PendingTransactions{}, ParseNodeError("get-pending-transactions", res.Error)
+	}
+
+	txs := make([]TransactionData, 0, len(res.PendingTxs))
+	for i, data := range res.PendingTxs {
+		tx, err := fromProtoTransactionData(fmt.Sprintf("pending_txs[%d].amount", i), data)
+		if err != nil {
+			return PendingTransactions{}, err
+		}
+		txs = append(txs, tx)
+	}
+	return PendingTransactions{TotalCount: res.TotalCount, Transactions: txs}, nil
 }
 
 func (c *MmnClient) GetCurrentNonce(ctx context.Context, addr string, tag string) (uint64, error) {
 	res, err := c.accClient.GetCurrentNonce(ctx, &mmnpb.GetCurrentNonceRequest{Address: addr, Tag: tag})
 	if err != nil {
-		return 0, err
+		return 0, FromRPCError("get-current-nonce", err)
+	}
+	if res.Error != "" {
+		return 0, ParseNodeError("get-current-nonce", res.Error)
 	}
 
 	return res.Nonce, nil
 }
 
+func (c *MmnClient) GetBlockNumber(ctx context.Context) (uint64, error) {
+	res, err := c.blockClient.GetBlockNumber(ctx, &mmnpb.EmptyParams{})
+	if err != nil {
+		return 0, FromRPCError("get-block-number", err)
+	}
+
+	return res.BlockNumber, nil
+}
+
+func (c *MmnClient) GetBlockByNumber(ctx context.Context, blockNumbers ...uint64) ([]Block, error) {
+	res, err := c.blockClient.GetBlockByNumber(ctx, &mmnpb.GetBlockByNumberRequest{BlockNumbers: blockNumbers})
+	if err != nil {
+		return nil, FromRPCError("get-block-by-number", err)
+	}
+	if res.Error != "" {
+		return nil, ParseNodeError("get-block-by-number", res.Error)
+	}
+
+	blocks := make([]Block, 0, len(res.Blocks))
+	for i, b := range res.Blocks {
+		block, err := fromProtoBlock(fmt.Sprintf("blocks[%d]", i), b, res.Decimals)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (c *MmnClient) GetBlockByRange(ctx context.Context, fromSlot, toSlot uint64) (BlockRange, error) {
+	res, err := c.blockClient.GetBlockByRange(ctx, &mmnpb.GetBlockByRangeRequest{FromSlot: fromSlot, ToSlot: toSlot})
+	if err != nil {
+		return BlockRange{}, FromRPCError("get-block-by-range", err)
+	}
+
+	blocks := make([]BlockInfo, 0, len(res.Blocks))
+	for i, b := range res.Blocks {
+		block, err := fromProtoBlockInfo(fmt.Sprintf("blocks[%d]", i), b, res.Decimals)
+		if err != nil {
+			return BlockRange{}, err
+		}
+		blocks = append(blocks, block)
+	}
+	return BlockRange{
+		Blocks:      blocks,
+		TotalBlocks: res.TotalBlocks,
+		Errors:      res.Errors,
+		Decimals:    res.Decimals,
+	}, nil
+}
+
 func (c *MmnClient) Conn() *grpc.ClientConn {
 	return c.conn
 }
@@ -1,10 +1,34 @@
 package client
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/holiman/uint256"
 	proto "github.com/mezonai/mmn-sdk/go-sdk/proto"
 )
 
+var ErrMalformedAmount = errors.New("convert: malformed amount")
+
+// AmountError reports an amount string received from the node that is not a valid uint256.
+type AmountError struct {
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *AmountError) Error() string {
+	return fmt.Sprintf("convert: invalid %s %q: %v", e.Field, e.Value, e.Err)
+}
+
+func (e *AmountError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AmountError) Is(target error) bool {
+	return target == ErrMalformedAmount
+}
+
 func ToProtoTx(tx *Tx) *proto.TxMsg {
 	return &proto.TxMsg{
 		Type:      int32(tx.Type),
@@ -27,12 +51,185 @@ func ToProtoSigTx(tx *SignedTx) *proto.SignedTxMsg {
 	}
 }
 
-func FromProtoAccount(acc *proto.GetAccountResponse) Account {
+func FromProtoTx(msg *proto.TxMsg) (*Tx, error) {
+	amount, err := ParseUint256("tx_msg.amount", msg.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{
+		Type:      int(msg.Type),
+		Sender:    msg.Sender,
+		Recipient: msg.Recipient,
+		Amount:    amount,
+		Timestamp: msg.Timestamp,
+		TextData:  msg.TextData,
+		Nonce:     msg.Nonce,
+		ExtraInfo: msg.ExtraInfo,
+		ZkProof:   msg.ZkProof,
+		ZkPub:     msg.ZkPub,
+	}, nil
+}
+
+func FromProtoSigTx(msg *proto.SignedTxMsg) (SignedTx, error) {
+	if msg.TxMsg == nil {
+		return SignedTx{}, errors.New("convert: signed tx without tx_msg")
+	}
+	tx, err := FromProtoTx(msg.TxMsg)
+	if err != nil {
+		return SignedTx{}, err
+	}
+	return SignedTx{Tx: tx, Sig: msg.Signature}, nil
+}
+
+func FromProtoAccount(acc *proto.GetAccountResponse) (Account, error) {
+	balance, err := ParseUint256("balance", acc.Balance)
+	if err != nil {
+		return Account{}, err
+	}
+
 	return Account{
-		Address: acc.Address,
-		Balance: Uint256FromString(acc.Balance),
-		Nonce:   acc.Nonce,
+		Address:  acc.Address,
+		Balance:  balance,
+		Nonce:    acc.Nonce,
+		Decimals: acc.Decimals,
+	}, nil
+}
+
+func FromProtoTxInfo(info *proto.TxInfo, decimals uint32) (TxInfo, error) {
+	amount, err := ParseUint256("tx.amount", info.Amount)
+	if err != nil {
+		return TxInfo{}, err
+	}
+
+	return TxInfo{
+		Sender:    info.Sender,
+		Recipient: info.Recipient,
+		Amount:    amount,
+		Timestamp: info.Timestamp,
+		TextData:  info.TextData,
+		Nonce:     info.Nonce,
+		Slot:      info.Slot,
+		Blockhash: info.Blockhash,
+		Status:    int32(info.Status),
+		ErrMsg:    info.ErrMsg,
+		ExtraInfo: info.ExtraInfo,
+		TxHash:    info.TxHash,
+		Decimals:  decimals,
+	}, nil
+}
+
+func FromProtoTxStatus(info *proto.TransactionStatusInfo) (TxStatusUpdate, error) {
+	amount, err := ParseUint256("amount", info.Amount)
+	if err != nil {
+		return TxStatusUpdate{}, err
+	}
+
+	return TxStatusUpdate{
+		TxHash:        info.TxHash,
+		Status:        TxMeta_Status(info.Status),
+		BlockSlot:     info.BlockSlot,
+		BlockHash:     info.BlockHash,
+		Confirmations: info.Confirmations,
+		ErrorMessage:  info.ErrorMessage,
+		Timestamp:     info.Timestamp,
+		ExtraInfo:     info.ExtraInfo,
+		Amount:        amount,
+		TextData:      info.TextData,
+		Sender:        info.Sender,
+		Recipient:     info.Recipient,
+	}, nil
+}
+
+func FromProtoTransactionData(data *proto.TransactionData) (TransactionData, error) {
+	return fromProtoTransactionData("transaction_data.amount", data)
+}
+
+func fromProtoTransactionData(field string, data *proto.TransactionData) (TransactionData, error) {
+	amount, err := ParseUint256(field, data.Amount)
+	if err != nil {
+		return TransactionData{}, err
+	}
+
+	return TransactionData{
+		TxHash:    data.TxHash,
+		Type:      int(data.TransactionType),
+		Sender:    data.Sender,
+		Recipient: data.Recipient,
+		Amount:    amount,
+		Nonce:     data.Nonce,
+		Timestamp: data.Timestamp,
+		Status:    TxMeta_Status(data.Status),
+		TextData:  data.TextData,
+		ExtraInfo: data.ExtraInfo,
+	}, nil
+}
+
+func fromProtoTransactionDataList(prefix string, list []*proto.TransactionData) ([]TransactionData, error) {
+	txs := make([]TransactionData, 0, len(list))
+	for i, data := range list {
+		tx, err := fromProtoTransactionData(fmt.Sprintf("%s.transaction_data[%d].amount", prefix, i), data)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
 	}
+	return txs, nil
+}
+
+func FromProtoBlock(block *proto.Block, decimals uint32) (Block, error) {
+	return fromProtoBlock("block", block, decimals)
+}
+
+func fromProtoBlock(prefix string, block *proto.Block, decimals uint32) (Block, error) {
+	txs, err := fromProtoTransactionDataList(prefix, block.TransactionData)
+	if err != nil {
+		return Block{}, err
+	}
+
+	entries := make([]Entry, 0, len(block.Entries))
+	for _, e := range block.Entries {
+		entries = append(entries, Entry{
+			NumHashes:    e.NumHashes,
+			Hash:         e.Hash,
+			Transactions: e.Transactions,
+			TxHashes:     e.TxHashes,
+		})
+	}
+
+	return Block{
+		Slot:         block.Slot,
+		PrevHash:     block.PrevHash,
+		Entries:      entries,
+		LeaderID:     block.LeaderId,
+		Timestamp:    block.Timestamp,
+		Hash:         block.Hash,
+		Signature:    block.Signature,
+		Transactions: txs,
+		Decimals:     decimals,
+	}, nil
+}
+
+func FromProtoBlockInfo(block *proto.BlockInfo, decimals uint32) (BlockInfo, error) {
+	return fromProtoBlockInfo("block", block, decimals)
+}
+
+func fromProtoBlockInfo(prefix string, block *proto.BlockInfo, decimals uint32) (BlockInfo, error) {
+	txs, err := fromProtoTransactionDataList(prefix, block.TransactionData)
+	if err != nil {
+		return BlockInfo{}, err
+	}
+
+	return BlockInfo{
+		Slot:         block.Slot,
+		PrevHash:     block.PrevHash,
+		LeaderID:     block.LeaderId,
+		Timestamp:    block.Timestamp,
+		Hash:         block.Hash,
+		Signature:    block.Signature,
+		Transactions: txs,
+		Decimals:     decimals,
+	}, nil
 }
 
 func Uint256ToString(value *uint256.Int) string {
@@ -42,13 +239,25 @@ func Uint256ToString(value *uint256.Int) string {
 	return value.String()
 }
 
+// Uint256FromString returns nil when value is not a valid decimal.
+// Prefer ParseUint256 for data received from a node.
 func Uint256FromString(value string) *uint256.Int {
+	amount, err := ParseUint256("", value)
+	if err != nil {
+		return nil
+	}
+	return amount
+}
+
+// ParseUint256 converts a decimal string into a uint256. An empty string is zero.
+// On failure it returns an *AmountError naming field.
+func ParseUint256(field, value string) (*uint256.Int, error) {
 	if value == "" {
-		return uint256.NewInt(0)
+		return uint256.NewInt(0), nil
 	}
 	amount, err := uint256.FromDecimal(value)
 	if err != nil {
-		return nil
+		return nil, &AmountError{Field: field, Value: value, Err: err}
 	}
-	return amount
+	return amount, nil
 }
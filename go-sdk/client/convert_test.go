@@ -0,0 +1,45 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+)
+
+func TestFromProtoAccount_MalformedBalance(t *testing.T) {
+	_, err := FromProtoAccount(&mmnpb.GetAccountResponse{Address: "addr", Balance: "12x"})
+	if !errors.Is(err, ErrMalformedAmount) {
+		t.Fatalf("FromProtoAccount() error = %v, want ErrMalformedAmount", err)
+	}
+
+	var amountErr *AmountError
+	if !errors.As(err, &amountErr) || amountErr.Field != "balance" || amountErr.Value != "12x" {
+		t.Fatalf("FromProtoAccount() error = %#v, want AmountError for balance", err)
+	}
+}
+
+func TestFromProtoBlockInfo_MalformedAmountField(t *testing.T) {
+	block := &mmnpb.BlockInfo{
+		TransactionData: []*mmnpb.TransactionData{
+			{TxHash: "a", Amount: "1"},
+			{TxHash: "b", Amount: "-5"},
+		},
+	}
+
+	_, err := fromProtoBlockInfo("blocks[2]", block, 0)
+	var amountErr *AmountError
+	if !errors.As(err, &amountErr) {
+		t.Fatalf("fromProtoBlockInfo() error = %v, want AmountError", err)
+	}
+	if amountErr.Field != "blocks[2].transaction_data[1].amount" {
+		t.Errorf("AmountError.Field = %q", amountErr.Field)
+	}
+}
+
+func TestSerialize_NilAmount(t *testing.T) {
+	tx := &Tx{Type: TxTypeUserContent, Sender: "s", Recipient: "r"}
+	if got := string(Serialize(tx)); got != "2|s|r|0||0|" {
+		t.Errorf("Serialize() = %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"crypto/ed25519"
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,11 +16,18 @@ import (
 var ErrUnsupportedKey = errors.New("crypto: unsupported private key length")
 
 func Serialize(tx *Tx) []byte {
-	amountStr := tx.Amount.String()
+	amountStr := Uint256ToString(tx.Amount)
 	metadata := fmt.Sprintf("%d|%s|%s|%s|%s|%d|%s", tx.Type, tx.Sender, tx.Recipient, amountStr, tx.TextData, tx.Nonce, tx.ExtraInfo)
 	return []byte(metadata)
 }
 
+// TxID identifies tx by the hex SHA-256 of its signing payload, which covers the sender
+// and nonce. It is computed locally and is not necessarily the hash the node assigns.
+func TxID(tx *Tx) string {
+	sum := sha256.Sum256(Serialize(tx))
+	return hex.EncodeToString(sum[:])
+}
+
 func SignTx(tx *Tx, pubKey, privKey []byte) (SignedTx, error) {
 	switch l := len(privKey); l {
 	case ed25519.SeedSize:
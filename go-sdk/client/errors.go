@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrNonceTooLow         = errors.New("node: nonce too low")
+	ErrNonceTooHigh        = errors.New("node: nonce too high")
+	ErrInsufficientBalance = errors.New("node: insufficient balance")
+	ErrInvalidSignature    = errors.New("node: invalid signature")
+	ErrDuplicateTx         = errors.New("node: duplicate transaction")
+	ErrTxNotFound          = errors.New("node: transaction not found")
+	ErrAccountNotFound     = errors.New("node: account not found")
+	ErrNodeUnavailable     = errors.New("node: unavailable")
+	ErrZkProofInvalid      = errors.New("node: invalid zk proof")
+)
+
+// nodeErrorPatterns maps lower-cased fragments of node error messages to sentinel errors.
+// Order matters: the first matching fragment wins.
+var nodeErrorPatterns = []struct {
+	fragment string
+	kind     error
+}{
+	{"nonce too low", ErrNonceTooLow},
+	{"nonce is too low", ErrNonceTooLow},
+	{"nonce too high", ErrNonceTooHigh},
+	{"nonce is too high", ErrNonceTooHigh},
+	{"insufficient balance", ErrInsufficientBalance},
+	{"insufficient funds", ErrInsufficientBalance},
+	{"zk proof", ErrZkProofInvalid},
+	{"zk verify", ErrZkProofInvalid},
+	{"invalid proof", ErrZkProofInvalid},
+	{"invalid signature", ErrInvalidSignature},
+	{"signature verification failed", ErrInvalidSignature},
+	{"duplicate tx", ErrDuplicateTx},
+	{"duplicate transaction", ErrDuplicateTx},
+	{"already exists", ErrDuplicateTx},
+	{"account not found", ErrAccountNotFound},
+	{"account does not exist", ErrAccountNotFound},
+	{"tx not found", ErrTxNotFound},
+	{"transaction not found", ErrTxNotFound},
+}
+
+// NodeError is a failure reported by the node, either in the error field of a
+// response or as a gRPC status. Use errors.Is with the Err* sentinels to classify it.
+type NodeError struct {
+	Op      string
+	Code    codes.Code
+	Message string
+	Kind    error
+}
+
+func (e *NodeError) Error() string {
+	if e.Code != codes.OK {
+		return fmt.Sprintf("%s failed: %s: %s", e.Op, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s failed: %s", e.Op, e.Message)
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Kind
+}
+
+// GRPCStatus keeps the original status visible to status.FromError and status.Code.
+// Failures reported in a response body carry codes.OK; they get the code matching
+// their kind, or codes.Unknown, so that a failed call never reports OK.
+func (e *NodeError) GRPCStatus() *status.Status {
+	code := e.Code
+	if code == codes.OK {
+		code = kindCode(e.Kind)
+	}
+	return status.New(code, e.Message)
+}
+
+// kindCode is the gRPC code for a body-level failure of the given kind.
+func kindCode(kind error) codes.Code {
+	switch kind {
+	case ErrNonceTooLow, ErrNonceTooHigh, ErrInsufficientBalance:
+		return codes.FailedPrecondition
+	case ErrInvalidSignature, ErrZkProofInvalid:
+		return codes.InvalidArgument
+	case ErrDuplicateTx:
+		return codes.AlreadyExists
+	case ErrTxNotFound, ErrAccountNotFound:
+		return codes.NotFound
+	case ErrNodeUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Unknown
+}
+
+// Retryable reports whether the same request may succeed if sent again unchanged.
+func (e *NodeError) Retryable() bool {
+	return errors.Is(e.Kind, ErrNodeUnavailable)
+}
+
+// IsRetryable reports whether err is a transient node or transport failure.
+func IsRetryable(err error) bool {
+	var nodeErr *NodeError
+	if errors.As(err, &nodeErr) {
+		return nodeErr.Retryable()
+	}
+	return errors.Is(err, ErrNodeUnavailable)
+}
+
+// ParseNodeError converts an error string from a node response into a *NodeError.
+// It returns nil for an empty message.
+func ParseNodeError(op, message string) error {
+	if message == "" {
+		return nil
+	}
+	return &NodeError{Op: op, Code: codes.OK, Message: message, Kind: matchNodeError(message)}
+}
+
+// FromRPCError converts a gRPC error into a *NodeError, classifying it by status code
+// and, failing that, by message. Non-status errors are returned unchanged.
+func FromRPCError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	var kind error
+	switch st.Code() {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		kind = ErrNodeUnavailable
+	case codes.Canceled:
+		kind = context.Canceled
+	case codes.DeadlineExceeded:
+		kind = context.DeadlineExceeded
+	default:
+		kind = matchNodeError(st.Message())
+	}
+
+	return &NodeError{Op: op, Code: st.Code(), Message: st.Message(), Kind: kind}
+}
+
+// fromRPCErrorNotFound is FromRPCError with codes.NotFound classified as notFound.
+func fromRPCErrorNotFound(op string, err error, notFound error) error {
+	err = FromRPCError(op, err)
+	var nodeErr *NodeError
+	if errors.As(err, &nodeErr) && nodeErr.Code == codes.NotFound {
+		nodeErr.Kind = notFound
+	}
+	return err
+}
+
+func matchNodeError(message string) error {
+	lower := strings.ToLower(message)
+	for _, p := range nodeErrorPatterns {
+		if strings.Contains(lower, p.fragment) {
+			return p.kind
+		}
+	}
+	return nil
+}
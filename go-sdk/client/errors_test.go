@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseNodeError(t *testing.T) {
+	cases := []struct {
+		message string
+		want    error
+	}{
+		{"invalid nonce: nonce too low (got 3, expected 5)", ErrNonceTooLow},
+		{"Insufficient balance for transfer", ErrInsufficientBalance},
+		{"invalid signature", ErrInvalidSignature},
+		{"zk proof verification failed", ErrZkProofInvalid},
+		{"tx not found", ErrTxNotFound},
+		{"duplicate transaction", ErrDuplicateTx},
+	}
+	for _, c := range cases {
+		err := ParseNodeError("add-tx", c.message)
+		if !errors.Is(err, c.want) {
+			t.Errorf("ParseNodeError(%q) = %v, want %v", c.message, err, c.want)
+		}
+		if err.Error() != "add-tx failed: "+c.message {
+			t.Errorf("ParseNodeError(%q).Error() = %q", c.message, err.Error())
+		}
+		if code := status.Code(err); code == codes.OK {
+			t.Errorf("status.Code(ParseNodeError(%q)) = OK", c.message)
+		}
+	}
+	// Only a duplicate transaction means the node may already have it.
+	if err := ParseNodeError("add-tx", "duplicate nonce"); errors.Is(err, ErrDuplicateTx) {
+		t.Errorf("ParseNodeError(duplicate nonce) = %v, want no ErrDuplicateTx", err)
+	}
+	if code := status.Code(ParseNodeError("add-tx", "duplicate tx")); code != codes.AlreadyExists {
+		t.Errorf("status.Code(duplicate) = %v, want AlreadyExists", code)
+	}
+
+	if err := ParseNodeError("add-tx", ""); err != nil {
+		t.Errorf("ParseNodeError(\"\") = %v, want nil", err)
+	}
+
+	var nodeErr *NodeError
+	if err := ParseNodeError("add-tx", "something odd"); !errors.As(err, &nodeErr) || nodeErr.Kind != nil {
+		t.Errorf("ParseNodeError(unknown) = %#v, want NodeError without kind", err)
+	}
+	if code := status.Code(nodeErr); code != codes.Unknown {
+		t.Errorf("status.Code(unknown) = %v, want Unknown", code)
+	}
+}
+
+func TestFromRPCError(t *testing.T) {
+	err := FromRPCError("add-tx", status.Error(codes.Unavailable, "connection refused"))
+	if !errors.Is(err, ErrNodeUnavailable) || !IsRetryable(err) {
+		t.Errorf("FromRPCError(Unavailable) = %v, want retryable ErrNodeUnavailable", err)
+	}
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("status.Code() = %v, want Unavailable", status.Code(err))
+	}
+
+	err = FromRPCError("add-tx", status.Error(codes.InvalidArgument, "nonce too low"))
+	if !errors.Is(err, ErrNonceTooLow) || IsRetryable(err) {
+		t.Errorf("FromRPCError(InvalidArgument) = %v, want non-retryable ErrNonceTooLow", err)
+	}
+
+	err = FromRPCError("add-tx", status.Error(codes.DeadlineExceeded, "deadline"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FromRPCError(DeadlineExceeded) = %v, want context.DeadlineExceeded", err)
+	}
+
+	err = fromRPCErrorNotFound("get-tx-by-hash", status.Error(codes.NotFound, "missing"), ErrTxNotFound)
+	if !errors.Is(err, ErrTxNotFound) {
+		t.Errorf("fromRPCErrorNotFound() = %v, want ErrTxNotFound", err)
+	}
+
+	plain := errors.New("plain")
+	if FromRPCError("add-tx", plain) != plain {
+		t.Errorf("FromRPCError() should pass through non-status errors")
+	}
+}
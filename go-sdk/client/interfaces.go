@@ -9,11 +9,25 @@ import (
 
 type MainnetClient interface {
 	AddTx(ctx context.Context, tx SignedTx) (AddTxResponse, error)
+	AddTxBatch(ctx context.Context, txs []SignedTx, opts BatchOptions) ([]BatchItemResult, error)
+	SimulateTx(ctx context.Context, tx SignedTx) (SimulationResult, error)
 	GetAccount(ctx context.Context, addr string) (Account, error)
+	GetAccounts(ctx context.Context, addrs ...string) ([]AccountResult, error)
 	SubscribeTransactionStatus(ctx context.Context) (mmnpb.TxService_SubscribeTransactionStatusClient, error)
 	GetTxByHash(ctx context.Context, txHash string) (TxInfo, error)
+	GetPendingTransactions(ctx context.Context) (PendingTransactions, error)
 	CheckHealth(ctx context.Context) (*mmnpb.HealthCheckResponse, error)
 	GetCurrentNonce(ctx context.Context, addr string, tag string) (uint64, error)
+	GetBlockNumber(ctx context.Context) (uint64, error)
+	GetBlockByNumber(ctx context.Context, blockNumbers ...uint64) ([]Block, error)
+	GetBlockByRange(ctx context.Context, fromSlot, toSlot uint64) (BlockRange, error)
 	Conn() *grpc.ClientConn
 	Close() error
 }
+
+// BlockSource is the part of MainnetClient that follows blocks, as used by the export
+// and indexer packages.
+type BlockSource interface {
+	GetBlockNumber(ctx context.Context) (uint64, error)
+	GetBlockByRange(ctx context.Context, fromSlot, toSlot uint64) (BlockRange, error)
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/holiman/uint256"
+)
+
+var (
+	ErrNotPending         = errors.New("replace: transaction is not pending")
+	ErrReplaceUnsupported = errors.New("replace: node rejected a transaction reusing a pending nonce")
+)
+
+// ReplaceOutcome is which version of a replaced transaction the chain kept.
+type ReplaceOutcome string
+
+const (
+	// OutcomePending means neither version is settled yet, or the node does not know
+	// them.
+	OutcomePending ReplaceOutcome = "pending"
+	// OutcomeReplaced means the replacement, or the cancellation, was finalized.
+	OutcomeReplaced ReplaceOutcome = "replaced"
+	// OutcomeOriginal means the original was finalized before the replacement took effect.
+	OutcomeOriginal ReplaceOutcome = "original"
+	// OutcomeFailed means both versions failed.
+	OutcomeFailed ReplaceOutcome = "failed"
+)
+
+// Replacement is a transaction sent to take the nonce of a pending one.
+type Replacement struct {
+	Original        SignedTx
+	OriginalHash    string
+	Replacement     SignedTx
+	ReplacementHash string
+	// Cancel is set for a self-transfer sent by CancelTx.
+	Cancel bool
+}
+
+// BuildReplacementTx returns a copy of orig with a new recipient and amount and the same
+// nonce. ZK transfers keep their proof, which binds the sender and key but not the transfer.
+func BuildReplacementTx(orig *Tx, recipient string, amount *uint256.Int) (*Tx, error) {
+	if err := ValidateAddress(recipient); err != nil {
+		return nil, fmt.Errorf("recipient: %w", err)
+	}
+	if (amount == nil || amount.IsZero()) && orig.Type != TxTypeUserContent {
+		return nil, ErrInvalidAmount
+	}
+	tx := *orig
+	tx.Recipient = recipient
+	tx.Amount = new(uint256.Int).Set(amount)
+	tx.Timestamp = uint64(time.Now().Unix())
+	return &tx, nil
+}
+
+// BuildCancelTx returns a transfer of the smallest amount, 1 base unit, from the sender
+// of orig to itself with the same nonce, so nothing leaves the account. Zero amounts are
+// rejected like any other transfer. It carries no text or extra info.
+func BuildCancelTx(orig *Tx) *Tx {
+	tx := *orig
+	tx.Recipient = orig.Sender
+	tx.Amount = uint256.NewInt(1)
+	tx.TextData = ""
+	tx.ExtraInfo = ""
+	tx.Timestamp = uint64(time.Now().Unix())
+	return &tx
+}
+
+// ReplaceTx signs a transaction with the nonce of orig paying amount to recipient and
+// sends it. orig must still be in the mempool, and the node must accept replacement
+// by nonce; otherwise the error matches ErrNotPending or ErrReplaceUnsupported.
+func (c *MmnClient) ReplaceTx(ctx context.Context, orig SignedTx, recipient string, amount *uint256.Int, pubKey, privKey []byte) (Replacement, error) {
+	tx, err := BuildReplacementTx(orig.Tx, recipient, amount)
+	if err != nil {
+		return Replacement{}, err
+	}
+	return c.sendReplacement(ctx, orig, tx, false, pubKey, privKey)
+}
+
+// CancelTx replaces orig with a self-transfer built by BuildCancelTx, see ReplaceTx.
+func (c *MmnClient) CancelTx(ctx context.Context, orig SignedTx, pubKey, privKey []byte) (Replacement, error) {
+	return c.sendReplacement(ctx, orig, BuildCancelTx(orig.Tx), true, pubKey, privKey)
+}
+
+func (c *MmnClient) sendReplacement(ctx context.Context, orig SignedTx, tx *Tx, cancel bool, pubKey, privKey []byte) (Replacement, error) {
+	origHash, err := c.pendingHash(ctx, orig.Tx)
+	if err != nil {
+		return Replacement{}, err
+	}
+	signed, err := SignTx(tx, pubKey, privKey)
+	if err != nil {
+		return Replacement{}, err
+	}
+
+	r := Replacement{Original: orig, OriginalHash: origHash, Replacement: signed, Cancel: cancel}
+	res, err := c.AddTx(ctx, signed)
+	if errors.Is(err, ErrNonceTooLow) || errors.Is(err, ErrDuplicateTx) {
+		return r, fmt.Errorf("%w: %w", ErrReplaceUnsupported, err)
+	}
+	if err != nil {
+		return r, err
+	}
+	r.ReplacementHash = res.TxHash
+	return r, nil
+}
+
+// pendingHash returns the mempool hash of the transaction with the sender and nonce of tx.
+func (c *MmnClient) pendingHash(ctx context.Context, tx *Tx) (string, error) {
+	pending, err := c.GetPendingTransactions(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range pending.Transactions {
+		if p.Sender == tx.Sender && p.Nonce == tx.Nonce {
+			return p.TxHash, nil
+		}
+	}
+	return "", fmt.Errorf("%w: sender %s nonce %d", ErrNotPending, tx.Sender, tx.Nonce)
+}
+
+// ReplacementOutcome looks up both versions of r and reports which one the chain kept.
+func (c *MmnClient) ReplacementOutcome(ctx context.Context, r Replacement) (ReplaceOutcome, error) {
+	orig, err := c.replacementStatus(ctx, r.OriginalHash)
+	if err != nil {
+		return OutcomePending, err
+	}
+	repl, err := c.replacementStatus(ctx, r.ReplacementHash)
+	if err != nil {
+		return OutcomePending, err
+	}
+
+	switch {
+	case repl == TxMeta_Status_FINALIZED:
+		return OutcomeReplaced, nil
+	case orig == TxMeta_Status_FINALIZED:
+		return OutcomeOriginal, nil
+	case orig == TxMeta_Status_FAILED && repl == TxMeta_Status_FAILED:
+		return OutcomeFailed, nil
+	}
+	return OutcomePending, nil
+}
+
+// replacementStatus returns the status of hash. An empty hash, for a replacement the node
+// refused, counts as failed; a hash the node does not know counts as pending, since a
+// lookup miss does not show that the transaction was dropped.
+func (c *MmnClient) replacementStatus(ctx context.Context, hash string) (TxMeta_Status, error) {
+	if hash == "" {
+		return TxMeta_Status_FAILED, nil
+	}
+	info, err := c.GetTxByHash(ctx, hash)
+	if errors.Is(err, ErrTxNotFound) {
+		return TxMeta_Status_PENDING, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return TxMeta_Status(info.Status), nil
+}
@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mezonai/mmn-sdk/go-sdk/mmntest"
+)
+
+func TestClient_ReplaceAndCancelTx(t *testing.T) {
+	a, to, fixed := newBatchSender(), newBatchSender(), newBatchSender()
+	node := mmntest.NewNode(mmntest.WithAccount(a.addr, uint256.NewInt(100), 0), mmntest.WithReplacement())
+	defer node.Close()
+	c, err := node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	txs := a.transfers(t, to.addr, 1, 2)
+	if _, err := c.AddTxBatch(ctx, txs, client.BatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	replaced, err := c.ReplaceTx(ctx, txs[0], fixed.addr, uint256.NewInt(5), a.pub, a.priv.Seed())
+	if err != nil {
+		t.Fatalf("ReplaceTx() error = %v", err)
+	}
+	cancelled, err := c.CancelTx(ctx, txs[1], a.pub, a.priv.Seed())
+	if err != nil {
+		t.Fatalf("CancelTx() error = %v", err)
+	}
+	if amount := cancelled.Replacement.Tx.Amount; amount.IsZero() || cancelled.Replacement.Tx.Recipient != a.addr {
+		t.Errorf("cancel transaction pays %s to %s, want a non-zero self-transfer", amount, cancelled.Replacement.Tx.Recipient)
+	}
+	if outcome, _ := c.ReplacementOutcome(ctx, replaced); outcome != client.OutcomePending {
+		t.Errorf("outcome before block = %s, want pending", outcome)
+	}
+
+	node.ProduceBlock()
+	for _, r := range []client.Replacement{replaced, cancelled} {
+		if outcome, err := c.ReplacementOutcome(ctx, r); err != nil || outcome != client.OutcomeReplaced {
+			t.Errorf("ReplacementOutcome(cancel=%v) = %s, %v, want replaced", r.Cancel, outcome, err)
+		}
+	}
+	if got := node.Balance(fixed.addr).Uint64(); got != 5 || node.Balance(a.addr).Uint64() != 95 {
+		t.Errorf("balances after replacement: fixed = %d, sender = %s", got, node.Balance(a.addr))
+	}
+
+	if _, err := c.CancelTx(ctx, txs[0], a.pub, a.priv.Seed()); !errors.Is(err, client.ErrNotPending) {
+		t.Errorf("CancelTx() of settled tx error = %v, want ErrNotPending", err)
+	}
+}
+
+func TestClient_ReplaceTx_Unsupported(t *testing.T) {
+	a, to := newBatchSender(), newBatchSender()
+	node := mmntest.NewNode(mmntest.WithAccount(a.addr, uint256.NewInt(100), 0))
+	defer node.Close()
+	c, err := node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	tx := a.transfers(t, to.addr, 1)[0]
+	if _, err := c.AddTx(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.CancelTx(ctx, tx, a.pub, a.priv.Seed())
+	if !errors.Is(err, client.ErrReplaceUnsupported) || !errors.Is(err, client.ErrNonceTooLow) {
+		t.Fatalf("CancelTx() error = %v, want ErrReplaceUnsupported", err)
+	}
+
+	node.ProduceBlock()
+	if outcome, _ := c.ReplacementOutcome(ctx, r); outcome != client.OutcomeOriginal {
+		t.Errorf("ReplacementOutcome() = %s, want original", outcome)
+	}
+
+	// Hashes the node does not know prove nothing was dropped.
+	unknown := client.Replacement{OriginalHash: "unknown-original", ReplacementHash: "unknown-replacement"}
+	if outcome, err := c.ReplacementOutcome(ctx, unknown); err != nil || outcome != client.OutcomePending {
+		t.Errorf("ReplacementOutcome() of unknown hashes = %s, %v, want pending", outcome, err)
+	}
+}
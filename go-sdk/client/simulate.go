@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/holiman/uint256"
+)
+
+// SimCheck names a check run by SimulateTx.
+type SimCheck string
+
+const (
+	SimCheckAddress   SimCheck = "address"
+	SimCheckAmount    SimCheck = "amount"
+	SimCheckSignature SimCheck = "signature"
+	SimCheckZkProof   SimCheck = "zk_proof"
+	SimCheckNonce     SimCheck = "nonce"
+	SimCheckBalance   SimCheck = "balance"
+)
+
+// SimProblem is one reason the node would reject a transaction. Err matches the
+// sentinel AddTx would return, such as ErrNonceTooLow or ErrInvalidSignature.
+type SimProblem struct {
+	Check SimCheck
+	Err   error
+}
+
+func (p SimProblem) Error() string {
+	return fmt.Sprintf("%s: %v", p.Check, p.Err)
+}
+
+func (p SimProblem) Unwrap() error {
+	return p.Err
+}
+
+// SimulationResult is the outcome of SimulateTx. PendingNonce, Balance and PendingSpend
+// are the chain state the transaction was checked against; PendingSpend is what the
+// sender's transactions in the mempool already spend of Balance.
+type SimulationResult struct {
+	Problems     []SimProblem
+	PendingNonce uint64
+	Balance      *uint256.Int
+	PendingSpend *uint256.Int
+}
+
+// OK reports whether no problem was found.
+func (r SimulationResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Err joins the problems into one error, or returns nil if there are none.
+func (r SimulationResult) Err() error {
+	errs := make([]error, 0, len(r.Problems))
+	for _, p := range r.Problems {
+		errs = append(errs, p)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *SimulationResult) add(check SimCheck, err error) {
+	r.Problems = append(r.Problems, SimProblem{Check: check, Err: err})
+}
+
+// SimulateTx runs the checks the node runs on AddTx without submitting tx: addresses,
+// amount, signature, the ZK proof when Config.ZkVerifier is set, the nonce against the
+// pending nonce and the amount against the sender's balance less its pending spends. Rejections are reported
+// as problems in the result; the error is only set when the node could not be queried.
+func (c *MmnClient) SimulateTx(ctx context.Context, tx SignedTx) (SimulationResult, error) {
+	var res SimulationResult
+	if tx.Tx == nil {
+		res.add(SimCheckSignature, errors.New("missing transaction"))
+		return res, nil
+	}
+
+	checkTxStatic(&res, tx)
+	if c.cfg.ZkVerifier != nil {
+		if err := VerifySignedZkProof(c.cfg.ZkVerifier, tx); err != nil {
+			res.add(SimCheckZkProof, err)
+		}
+	}
+
+	pending, err := c.GetCurrentNonce(ctx, tx.Tx.Sender, "pending")
+	if err != nil {
+		return res, err
+	}
+	res.PendingNonce = pending
+	switch {
+	case tx.Tx.Nonce <= pending:
+		res.add(SimCheckNonce, fmt.Errorf("%w: got %d, next is %d", ErrNonceTooLow, tx.Tx.Nonce, pending+1))
+	case tx.Tx.Nonce > pending+1:
+		res.add(SimCheckNonce, fmt.Errorf("%w: got %d, next is %d", ErrNonceTooHigh, tx.Tx.Nonce, pending+1))
+	}
+
+	amount := tx.Tx.Amount
+	if amount == nil {
+		amount = uint256.NewInt(0)
+	}
+	spend, err := c.pendingSpend(ctx, tx.Tx)
+	if err != nil {
+		return res, err
+	}
+	res.PendingSpend = spend
+	need, overflow := new(uint256.Int).AddOverflow(spend, amount)
+	acc, err := c.GetAccount(ctx, tx.Tx.Sender)
+	switch {
+	case errors.Is(err, ErrAccountNotFound):
+		res.Balance = uint256.NewInt(0)
+		if !need.IsZero() {
+			res.add(SimCheckBalance, err)
+		}
+	case err != nil:
+		return res, err
+	default:
+		res.Balance = acc.Balance
+		if overflow || acc.Balance.Lt(need) {
+			res.add(SimCheckBalance, fmt.Errorf("%w: balance %s, pending %s, amount %s",
+				ErrInsufficientBalance, acc.Balance, spend, amount))
+		}
+	}
+	return res, nil
+}
+
+// pendingSpend sums the amounts of the sender's other transactions in the mempool. A
+// pending transaction with the same nonce as tx is left out, as tx would replace it.
+func (c *MmnClient) pendingSpend(ctx context.Context, tx *Tx) (*uint256.Int, error) {
+	pending, err := c.GetPendingTransactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sum := uint256.NewInt(0)
+	for _, p := range pending.Transactions {
+		if p.Sender == tx.Sender && p.Nonce != tx.Nonce && p.Amount != nil {
+			sum.Add(sum, p.Amount)
+		}
+	}
+	return sum, nil
+}
+
+// checkTxStatic runs the checks that need no chain state.
+func checkTxStatic(res *SimulationResult, tx SignedTx) {
+	if err := ValidateAddress(tx.Tx.Sender); err != nil {
+		res.add(SimCheckAddress, fmt.Errorf("sender: %w", err))
+	} else if tx.Tx.Type == TxTypeTransferByKey && !isCurvePoint(tx.Tx.Sender) {
+		res.add(SimCheckAddress, fmt.Errorf("sender: %w: not an ed25519 public key", ErrInvalidAddress))
+	}
+	if err := ValidateAddress(tx.Tx.Recipient); err != nil {
+		res.add(SimCheckAddress, fmt.Errorf("recipient: %w", err))
+	} else if !ValidateTxAddresses(tx.Tx) {
+		res.add(SimCheckAddress, fmt.Errorf("recipient: %w: not an ed25519 public key", ErrInvalidAddress))
+	}
+
+	if (tx.Tx.Amount == nil || tx.Tx.Amount.IsZero()) && tx.Tx.Type != TxTypeUserContent {
+		res.add(SimCheckAmount, ErrInvalidAmount)
+	}
+	if !Verify(tx.Tx, tx.Sig) {
+		res.add(SimCheckSignature, ErrInvalidSignature)
+	}
+}
+
+func isCurvePoint(addr string) bool {
+	a, err := ParseAddress(addr)
+	return err == nil && a.IsKey()
+}
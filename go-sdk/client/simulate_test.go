@@ -0,0 +1,93 @@
+package client_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mezonai/mmn-sdk/go-sdk/mmntest"
+	"github.com/mr-tron/base58"
+)
+
+func TestClient_SimulateTx(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	sender := base58.Encode(pub)
+	recipientPub, _, _ := ed25519.GenerateKey(nil)
+	recipient := base58.Encode(recipientPub)
+
+	node := mmntest.NewNode(mmntest.WithAccount(sender, uint256.NewInt(100), 4))
+	defer node.Close()
+	c, err := node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	sign := func(amount, nonce uint64) client.SignedTx {
+		tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, sender, recipient, uint256.NewInt(amount), nonce, 0, "", nil, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := client.SignTx(tx, pub, priv.Seed())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	forged := sign(10, 5)
+	forged.Tx.Recipient = sender
+
+	tests := []struct {
+		name string
+		tx   client.SignedTx
+		want map[client.SimCheck]error
+	}{
+		{"valid", sign(10, 5), nil},
+		{"stale nonce", sign(10, 4), map[client.SimCheck]error{client.SimCheckNonce: client.ErrNonceTooLow}},
+		{"overspend and gap", sign(101, 7), map[client.SimCheck]error{
+			client.SimCheckNonce:   client.ErrNonceTooHigh,
+			client.SimCheckBalance: client.ErrInsufficientBalance,
+		}},
+		{"tampered", forged, map[client.SimCheck]error{client.SimCheckSignature: client.ErrInvalidSignature}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := c.SimulateTx(context.Background(), tt.tx)
+			if err != nil {
+				t.Fatalf("SimulateTx() error = %v", err)
+			}
+			if len(res.Problems) != len(tt.want) {
+				t.Fatalf("SimulateTx() problems = %v, want %d", res.Problems, len(tt.want))
+			}
+			for _, p := range res.Problems {
+				if !errors.Is(p, tt.want[p.Check]) {
+					t.Errorf("problem %v, want %v", p, tt.want[p.Check])
+				}
+			}
+			if res.PendingNonce != 4 || res.Balance.Uint64() != 100 {
+				t.Errorf("SimulateTx() state = nonce %d balance %s", res.PendingNonce, res.Balance)
+			}
+		})
+	}
+	if node.PendingCount() != 0 {
+		t.Errorf("SimulateTx submitted %d transactions", node.PendingCount())
+	}
+
+	// A pending spend leaves too little for the next transfer.
+	if _, err := c.AddTx(context.Background(), sign(60, 5)); err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.SimulateTx(context.Background(), sign(50, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Problems) != 1 || !errors.Is(res.Problems[0], client.ErrInsufficientBalance) || res.PendingSpend.Uint64() != 60 {
+		t.Errorf("SimulateTx() after a pending spend = %+v", res)
+	}
+	if _, err := c.AddTx(context.Background(), sign(50, 6)); !errors.Is(err, client.ErrInsufficientBalance) {
+		t.Errorf("AddTx() error = %v, want ErrInsufficientBalance", err)
+	}
+}
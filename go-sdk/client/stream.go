@@ -0,0 +1,24 @@
+package client
+
+import (
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+)
+
+// TxStatusStream decodes events of a SubscribeTransactionStatus stream.
+type TxStatusStream struct {
+	stream mmnpb.TxService_SubscribeTransactionStatusClient
+}
+
+func NewTxStatusStream(stream mmnpb.TxService_SubscribeTransactionStatusClient) *TxStatusStream {
+	return &TxStatusStream{stream: stream}
+}
+
+// Recv returns the next status update. A malformed amount yields an *AmountError
+// and does not end the stream.
+func (s *TxStatusStream) Recv() (TxStatusUpdate, error) {
+	info, err := s.stream.Recv()
+	if err != nil {
+		return TxStatusUpdate{}, err
+	}
+	return FromProtoTxStatus(info)
+}
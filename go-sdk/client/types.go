@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/holiman/uint256"
 	"github.com/mr-tron/base58"
@@ -25,9 +26,28 @@ var (
 
 // ----- Account -----
 type Account struct {
+	Address  string
+	Balance  *uint256.Int
+	Nonce    uint64
+	Decimals uint32
+}
+
+// AccountResult is one entry of a GetAccounts batch. Err wraps ErrAccountNotFound
+// when the node has no such account.
+type AccountResult struct {
 	Address string
-	Balance *uint256.Int
-	Nonce   uint64
+	Account Account
+	Err     error
+}
+
+// FormatBalance renders the balance as a decimal string using the node reported decimals.
+func (a Account) FormatBalance() string {
+	return FormatAmount(a.Balance, a.Decimals)
+}
+
+// CheckDecimals reports ErrDecimalsMismatch when the node scale differs from NATIVE_DECIMAL.
+func (a Account) CheckDecimals() error {
+	return CheckDecimals(a.Decimals)
 }
 
 func ValidateAddress(addr string) error {
@@ -55,14 +75,41 @@ type Tx struct {
 	ZkPub     string       `json:"zk_pub"`
 }
 
+// BuildOption configures BuildTransferTx.
+type BuildOption func(*buildOptions)
+
+type buildOptions struct {
+	strict bool
+}
+
+// WithStrictAddresses makes BuildTransferTx reject a TxTypeTransferByKey transfer whose
+// sender or recipient is not an ed25519 public key. Such a sender cannot sign, and only
+// a ZK login can spend from such a recipient.
+func WithStrictAddresses() BuildOption {
+	return func(o *buildOptions) { o.strict = true }
+}
+
 func BuildTransferTx(txType int, sender, recipient string, amount *uint256.Int, nonce uint64, ts uint64, textData string,
-	extraInfo map[string]string, zkProof string, zkPub string) (*Tx, error) {
+	extraInfo map[string]string, zkProof string, zkPub string, opts ...BuildOption) (*Tx, error) {
+	var o buildOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	if err := ValidateAddress(sender); err != nil {
 		return nil, fmt.Errorf("from: %w", err)
 	}
 	if err := ValidateAddress(recipient); err != nil {
 		return nil, fmt.Errorf("recipient: %w", err)
 	}
+	if o.strict && txType == TxTypeTransferByKey {
+		if !isCurvePoint(sender) {
+			return nil, fmt.Errorf("from: %w", ErrUnreachableAddress)
+		}
+		if !isCurvePoint(recipient) {
+			return nil, fmt.Errorf("recipient: %w", ErrUnreachableAddress)
+		}
+	}
 	if (amount == nil || amount.IsZero()) && (txType != TxTypeUserContent) {
 		return nil, ErrInvalidAmount
 	}
@@ -135,6 +182,21 @@ const (
 	TxMeta_Status_FAILED    TxMeta_Status = 3
 )
 
+var txStatusNames = map[TxMeta_Status]string{
+	TxMeta_Status_PENDING:   "pending",
+	TxMeta_Status_CONFIRMED: "confirmed",
+	TxMeta_Status_FINALIZED: "finalized",
+	TxMeta_Status_FAILED:    "failed",
+}
+
+// String returns the lower-case status name, or the number of an unknown status.
+func (s TxMeta_Status) String() string {
+	if name, ok := txStatusNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
 type TxMetaResponse struct {
 	Sender    string
 	Recipient string
@@ -149,6 +211,22 @@ type TxHistoryResponse struct {
 	Txs   []*TxMetaResponse
 }
 
+// TxStatusUpdate is a decoded event from SubscribeTransactionStatus
+type TxStatusUpdate struct {
+	TxHash        string
+	Status        TxMeta_Status
+	BlockSlot     uint64
+	BlockHash     string
+	Confirmations uint64
+	ErrorMessage  string
+	Timestamp     uint64
+	ExtraInfo     string
+	Amount        *uint256.Int
+	TextData      string
+	Sender        string
+	Recipient     string
+}
+
 // TxInfo represents transaction information returned by GetTxByHash
 type TxInfo struct {
 	Sender    string       `json:"sender"`
@@ -163,12 +241,101 @@ type TxInfo struct {
 	ErrMsg    string       `json:"err_msg,omitempty"`
 	ExtraInfo string       `json:"extra_info,omitempty"`
 	TxHash    string       `json:"tx_hash,omitempty"`
+	Decimals  uint32       `json:"decimals,omitempty"`
 }
 
 func (i *TxInfo) DeserializedExtraInfo() (map[string]string, error) {
 	return DeserializeTxExtraInfo(i.ExtraInfo)
 }
 
+// FormatAmount renders the amount as a decimal string using the node reported decimals.
+func (i *TxInfo) FormatAmount() string {
+	return FormatAmount(i.Amount, i.Decimals)
+}
+
+// CheckDecimals reports ErrDecimalsMismatch when the node scale differs from NATIVE_DECIMAL.
+func (i *TxInfo) CheckDecimals() error {
+	return CheckDecimals(i.Decimals)
+}
+
+// ----- Block -----
+
+// TransactionData is the transaction summary carried inside blocks.
+type TransactionData struct {
+	TxHash    string        `json:"tx_hash"`
+	Type      int           `json:"type"`
+	Sender    string        `json:"sender"`
+	Recipient string        `json:"recipient"`
+	Amount    *uint256.Int  `json:"amount"`
+	Nonce     uint64        `json:"nonce"`
+	Timestamp uint64        `json:"timestamp"`
+	Status    TxMeta_Status `json:"status"`
+	TextData  string        `json:"text_data"`
+	ExtraInfo string        `json:"extra_info"`
+}
+
+// PendingTransactions is the mempool content returned by GetPendingTransactions.
+type PendingTransactions struct {
+	TotalCount   uint64
+	Transactions []TransactionData
+}
+
+type Entry struct {
+	NumHashes    uint64   `json:"num_hashes"`
+	Hash         []byte   `json:"hash"`
+	Transactions [][]byte `json:"transactions"`
+	TxHashes     []string `json:"tx_hashes"`
+}
+
+// Block is a full block including its PoH entries, returned by GetBlockByNumber.
+type Block struct {
+	Slot         uint64            `json:"slot"`
+	PrevHash     []byte            `json:"prev_hash"`
+	Entries      []Entry           `json:"entries"`
+	LeaderID     string            `json:"leader_id"`
+	Timestamp    uint64            `json:"timestamp"`
+	Hash         []byte            `json:"hash"`
+	Signature    []byte            `json:"signature"`
+	Transactions []TransactionData `json:"transaction_data"`
+	Decimals     uint32            `json:"decimals,omitempty"`
+}
+
+// FormatAmount renders a transaction amount of this block using the node reported decimals.
+func (b *Block) FormatAmount(amount *uint256.Int) string {
+	return FormatAmount(amount, b.Decimals)
+}
+
+// BlockInfo is a block without entries, returned by GetBlockByRange.
+type BlockInfo struct {
+	Slot         uint64            `json:"slot"`
+	PrevHash     []byte            `json:"prev_hash"`
+	LeaderID     string            `json:"leader_id"`
+	Timestamp    uint64            `json:"timestamp"`
+	Hash         []byte            `json:"hash"`
+	Signature    []byte            `json:"signature"`
+	Transactions []TransactionData `json:"transaction_data"`
+	Decimals     uint32            `json:"decimals,omitempty"`
+}
+
+// FormatAmount renders a transaction amount of this block using the node reported decimals.
+func (b *BlockInfo) FormatAmount(amount *uint256.Int) string {
+	return FormatAmount(amount, b.Decimals)
+}
+
+// BlockRange is the result of GetBlockByRange. Errors holds the per-slot
+// errors reported by the node, e.g. for skipped slots.
+type BlockRange struct {
+	Blocks      []BlockInfo
+	TotalBlocks uint32
+	Errors      []string
+	Decimals    uint32
+}
+
+// CheckDecimals reports ErrDecimalsMismatch when the node scale differs from NATIVE_DECIMAL.
+func (r BlockRange) CheckDecimals() error {
+	return CheckDecimals(r.Decimals)
+}
+
 const (
 	TransactionExtraInfoDongGiveCoffee       = "dong-give-coffee"
 	TransactionExtraInfoGiveCoffee           = "give-coffee"
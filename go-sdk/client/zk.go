@@ -0,0 +1,87 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/holiman/uint256"
+	"github.com/mr-tron/base58"
+)
+
+var ErrInvalidUserSig = errors.New("crypto: invalid user signature")
+
+// ZkProofVerifier checks a ZK transfer proof against the sender and public key it must bind to.
+// *zkverify.ZkVerify implements it.
+type ZkProofVerifier interface {
+	VerifyDetailed(sender, pubKey, proofB64, pubB64 string) error
+}
+
+// ZkProofError reports a ZK transfer proof rejected before submission. Err is the
+// verifier's reason, a *zkverify.VerifyError naming the failing stage when the
+// verifier is a *zkverify.ZkVerify. It matches ErrZkProofInvalid.
+type ZkProofError struct {
+	Sender string
+	PubKey string
+	Err    error
+}
+
+func (e *ZkProofError) Error() string {
+	return fmt.Sprintf("zk pre-flight: proof rejected for sender %s and pubkey %s: %v", e.Sender, e.PubKey, e.Err)
+}
+
+func (e *ZkProofError) Unwrap() []error {
+	return []error{ErrZkProofInvalid, e.Err}
+}
+
+// ParseUserSig decodes the signature of a ZK transfer as produced by SignTx.
+func ParseUserSig(sig string) (UserSig, error) {
+	sigBytes, err := base58.Decode(sig)
+	if err != nil {
+		return UserSig{}, fmt.Errorf("%w: %v", ErrInvalidUserSig, err)
+	}
+	var userSig UserSig
+	if err := json.Unmarshal(sigBytes, &userSig); err != nil {
+		return UserSig{}, fmt.Errorf("%w: %v", ErrInvalidUserSig, err)
+	}
+	return userSig, nil
+}
+
+// VerifyZkProof checks tx.ZkProof and tx.ZkPub against tx.Sender and the signer's public key.
+// Transactions other than TxTypeTransferByZk are not checked.
+func VerifyZkProof(v ZkProofVerifier, tx *Tx, pubKey []byte) error {
+	if tx.Type != TxTypeTransferByZk {
+		return nil
+	}
+	pubKeyStr := base58.Encode(pubKey)
+	if err := v.VerifyDetailed(tx.Sender, pubKeyStr, tx.ZkProof, tx.ZkPub); err != nil {
+		return &ZkProofError{Sender: tx.Sender, PubKey: pubKeyStr, Err: err}
+	}
+	return nil
+}
+
+// VerifySignedZkProof is VerifyZkProof with the public key taken from the UserSig of tx.
+func VerifySignedZkProof(v ZkProofVerifier, tx SignedTx) error {
+	if tx.Tx.Type != TxTypeTransferByZk {
+		return nil
+	}
+	userSig, err := ParseUserSig(tx.Sig)
+	if err != nil {
+		return err
+	}
+	return VerifyZkProof(v, tx.Tx, userSig.PubKey)
+}
+
+// BuildZkTransferTx is BuildTransferTx for TxTypeTransferByZk that also checks the proof
+// against sender and pubKey, the key that will sign the transaction.
+func BuildZkTransferTx(v ZkProofVerifier, pubKey []byte, sender, recipient string, amount *uint256.Int, nonce uint64, ts uint64, textData string,
+	extraInfo map[string]string, zkProof string, zkPub string) (*Tx, error) {
+	tx, err := BuildTransferTx(TxTypeTransferByZk, sender, recipient, amount, nonce, ts, textData, extraInfo, zkProof, zkPub)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyZkProof(v, tx, pubKey); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/zkcircuit"
+	"github.com/mezonai/mmn-sdk/go-sdk/zkverify"
+	"github.com/mr-tron/base58"
+)
+
+func TestVerifyZkProof_PreFlight(t *testing.T) {
+	keys, err := zkcircuit.Setup()
+	if err != nil {
+		t.Fatalf("zkcircuit.Setup() error = %v", err)
+	}
+	var vk bytes.Buffer
+	if err := keys.WriteVerifyingKey(&vk); err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := zkverify.NewZkVerifyFromBytes(vk.Bytes(), zkverify.EncodingBase64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	userID := "3767478432163172999"
+	sender := GenerateAddress(userID)
+	proof, err := keys.Prove(zkcircuit.Assignment{UserID: userID, Sender: sender, PubKey: base58.Encode(pub), Secret: big.NewInt(7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := base58.Encode(make([]byte, 32))
+
+	tx, err := BuildZkTransferTx(verifier, pub, sender, recipient, uint256.NewInt(1), 1, 0, "", nil, proof.ProofB64, proof.PubB64)
+	if err != nil {
+		t.Fatalf("BuildZkTransferTx() error = %v", err)
+	}
+	signed, err := SignTx(tx, pub, priv.Seed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignedZkProof(verifier, signed); err != nil {
+		t.Errorf("VerifySignedZkProof() = %v", err)
+	}
+
+	otherPub, otherPriv, _ := ed25519.GenerateKey(nil)
+	_, err = BuildZkTransferTx(verifier, otherPub, sender, recipient, uint256.NewInt(1), 1, 0, "", nil, proof.ProofB64, proof.PubB64)
+	var zkErr *ZkProofError
+	var verr *zkverify.VerifyError
+	if !errors.Is(err, ErrZkProofInvalid) || !errors.As(err, &zkErr) || !errors.As(err, &verr) || verr.Stage != zkverify.StagePubKeyMismatch {
+		t.Fatalf("BuildZkTransferTx() with wrong key error = %v, want pubkey mismatch", err)
+	}
+	if zkErr.PubKey != base58.Encode(otherPub) {
+		t.Errorf("ZkProofError.PubKey = %s", zkErr.PubKey)
+	}
+
+	resigned, err := SignTx(tx, otherPub, otherPriv.Seed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &MmnClient{cfg: Config{ZkVerifier: verifier}}
+	if _, err := client.AddTx(context.Background(), resigned); !errors.Is(err, ErrZkProofInvalid) {
+		t.Errorf("AddTx() error = %v, want ErrZkProofInvalid before broadcast", err)
+	}
+}
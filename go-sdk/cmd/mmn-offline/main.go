@@ -0,0 +1,215 @@
+// Command mmn-offline moves transfers between an online machine and an air-gapped signer.
+//
+//	mmn-offline export    -from ADDR -to ADDR -amount 1.5 -nonce N [-out FILE]   (online)
+//	mmn-offline sign      -in FILE -key KEYFILE [-out FILE]                    (offline)
+//	mmn-offline broadcast -in FILE -endpoint HOST:PORT [-tls]                  (online)
+//
+// Bundles are JSON by default; -base58 writes a single base58 line instead. Both forms
+// are accepted as input. sign prints the signing payload to stderr before signing.
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mr-tron/base58"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = runExport(os.Args[2:])
+	case "sign":
+		err = runSign(os.Args[2:])
+	case "broadcast":
+		err = runBroadcast(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mmn-offline:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: mmn-offline export|sign|broadcast [flags]")
+	os.Exit(2)
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	from := fs.String("from", "", "sender address")
+	to := fs.String("to", "", "recipient address")
+	amount := fs.String("amount", "", "amount in whole tokens, e.g. 1.5")
+	nonce := fs.Uint64("nonce", 0, "transaction nonce; fetched from -endpoint when 0")
+	text := fs.String("text", "", "text data")
+	endpoint := fs.String("endpoint", "", "node endpoint used to fetch the nonce")
+	useTLS := fs.Bool("tls", false, "use TLS for -endpoint")
+	out := fs.String("out", "", "output file (default stdout)")
+	b58 := fs.Bool("base58", false, "write the bundle as base58")
+	fs.Parse(args)
+
+	value, err := client.ParseAmount(*amount, client.NATIVE_DECIMAL)
+	if err != nil {
+		return err
+	}
+	if *nonce == 0 {
+		if *endpoint == "" {
+			return errors.New("export: -nonce or -endpoint is required")
+		}
+		c, err := client.NewClient(client.Config{Endpoint: *endpoint, UseTLS: *useTLS})
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		pending, err := c.GetCurrentNonce(ctx, *from, "pending")
+		if err != nil {
+			return err
+		}
+		*nonce = pending + 1
+	}
+
+	tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, *from, *to, value, *nonce,
+		uint64(time.Now().Unix()), *text, nil, "", "")
+	if err != nil {
+		return err
+	}
+	return writeBundle(*out, client.NewUnsignedBundle(tx), *b58)
+}
+
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	in := fs.String("in", "", "unsigned bundle file (default stdin)")
+	keyFile := fs.String("key", "", "file holding the ed25519 seed as hex or base58")
+	out := fs.String("out", "", "output file (default stdout)")
+	b58 := fs.Bool("base58", false, "write the bundle as base58")
+	fs.Parse(args)
+
+	b, err := readBundle(*in)
+	if err != nil {
+		return err
+	}
+	seed, err := readSeed(*keyFile)
+	if err != nil {
+		return err
+	}
+	pub := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+
+	fmt.Fprintf(os.Stderr, "signing payload: %s\ntx id: %s\n", b.Payload, b.TxID)
+	signed, err := b.Sign(pub, seed)
+	if err != nil {
+		return err
+	}
+	if _, err := signed.SignedTx(); err != nil {
+		return fmt.Errorf("sign: key %s does not match sender %s: %w", base58.Encode(pub), b.Tx.Sender, err)
+	}
+	return writeBundle(*out, signed, *b58)
+}
+
+func runBroadcast(args []string) error {
+	fs := flag.NewFlagSet("broadcast", flag.ExitOnError)
+	in := fs.String("in", "", "signed bundle file (default stdin)")
+	endpoint := fs.String("endpoint", "", "node endpoint")
+	useTLS := fs.Bool("tls", false, "use TLS")
+	fs.Parse(args)
+
+	b, err := readBundle(*in)
+	if err != nil {
+		return err
+	}
+	tx, err := b.SignedTx()
+	if err != nil {
+		return err
+	}
+	c, err := client.NewClient(client.Config{Endpoint: *endpoint, UseTLS: *useTLS})
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	res, err := c.AddTx(ctx, tx)
+	if err != nil {
+		return err
+	}
+	fmt.Println(res.TxHash)
+	return nil
+}
+
+func readBundle(path string) (client.Bundle, error) {
+	var data []byte
+	var err error
+	if path == "" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return client.Bundle{}, err
+	}
+	return client.ParseBundle(data)
+}
+
+func writeBundle(path string, b client.Bundle, b58 bool) error {
+	var data []byte
+	if b58 {
+		s, err := client.EncodeBundleBase58(b)
+		if err != nil {
+			return err
+		}
+		data = []byte(s)
+	} else {
+		var err error
+		if data, err = client.MarshalBundle(b); err != nil {
+			return err
+		}
+	}
+	data = append(data, '\n')
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// readSeed accepts a 32-byte seed or a 64-byte ed25519 private key, hex or base58 encoded.
+func readSeed(path string) ([]byte, error) {
+	if path == "" {
+		return nil, errors.New("sign: -key is required")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(string(raw))
+	key, err := hex.DecodeString(text)
+	if err != nil {
+		if key, err = base58.Decode(text); err != nil {
+			return nil, errors.New("sign: key is neither hex nor base58")
+		}
+	}
+	switch len(key) {
+	case ed25519.SeedSize:
+		return key, nil
+	case ed25519.PrivateKeySize:
+		return key[:ed25519.SeedSize], nil
+	}
+	return nil, fmt.Errorf("sign: key has %d bytes, want %d or %d", len(key), ed25519.SeedSize, ed25519.PrivateKeySize)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+)
+
+// The console runs the commands of this tool against one connection, with pretty output.
+// Besides the commands it understands:
+//
+//	@LABEL             an argument replaced by the address of a keystore key
+//	tail on [HASH...]  print status updates in the background, of HASH only if given
+//	tail off           stop tailing
+//	nonces             the nonces sent this session, used by tx send before the node's
+//	keystore           the keystore keys; their labels also work with -key
+//	help, exit
+//
+// Tab completes commands, subcommands, keystore labels and addresses.
+var consoleBuiltins = []string{"exit", "help", "keystore", "nonces", "quit", "tail"}
+
+type console struct {
+	e    *env
+	ed   *lineEditor
+	tail context.CancelFunc
+}
+
+func runConsole(e *env, args []string) error {
+	fs := e.flags("console [-keys DIR]")
+	dir := fs.String("keys", defaultKeystoreDir(), "keystore directory of key files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 0, false); err != nil {
+		return err
+	}
+	ks, err := loadKeystore(*dir)
+	if err != nil {
+		return err
+	}
+
+	// Interrupts cancel the running command, not the console.
+	if e.stop != nil {
+		e.stop()
+	}
+	con := &console{e: e}
+	con.ed = newLineEditor(e.in, e.out, con.complete)
+	e.out, e.errOut = con.ed, con.ed
+	e.pretty, e.nonces, e.keystore = true, make(map[string]sentTx), ks
+	defer con.stopTail()
+
+	fmt.Fprintf(e.out, "mmn console on %s, %d keys in %s; type help\n", e.cfg.Endpoint, len(ks), *dir)
+	for {
+		line, err := con.ed.ReadLine("mmn> ")
+		if err != nil {
+			return nil
+		}
+		args, err := splitLine(line)
+		if err != nil {
+			fmt.Fprintln(e.out, "error:", err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+		if err := con.exec(args); err != nil && !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(e.out, "error:", err)
+		}
+	}
+}
+
+func defaultKeystoreDir() string {
+	if dir := os.Getenv("MMN_KEYS"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "keys"
+	}
+	return filepath.Join(home, ".mmn", "keys")
+}
+
+func (con *console) exec(args []string) error {
+	e := con.e
+	switch args[0] {
+	case "help":
+		fmt.Fprintf(e.out, "commands: %s\nconsole: %s\n", strings.Join(sortedKeys(commands), " "), strings.Join(consoleBuiltins, " "))
+		return nil
+	case "tail":
+		return con.tailCmd(args[1:])
+	case "nonces":
+		tw := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+		for _, addr := range sortedAddrs(e.nonces) {
+			fmt.Fprintf(tw, "%s\t%d\n", addr, e.nonces[addr].nonce)
+		}
+		return tw.Flush()
+	case "keystore":
+		tw := tabwriter.NewWriter(e.out, 0, 0, 2, ' ', 0)
+		for _, label := range e.keystore.labels() {
+			fmt.Fprintf(tw, "%s\t%s\n", label, e.keystore[label].address)
+		}
+		return tw.Flush()
+	case "console":
+		return errors.New("already in the console")
+	}
+
+	for i, a := range args {
+		if !strings.HasPrefix(a, "@") {
+			continue
+		}
+		entry, ok := e.keystore[a[1:]]
+		if !ok {
+			return fmt.Errorf("no key labeled %q", a[1:])
+		}
+		args[i] = entry.address
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	e.base = ctx
+	return e.run(args)
+}
+
+func (con *console) tailCmd(args []string) error {
+	if len(args) == 0 || (args[0] != "on" && args[0] != "off") {
+		return errors.New("usage: tail on [HASH...] | tail off")
+	}
+	con.stopTail()
+	if args[0] == "off" {
+		return nil
+	}
+
+	c, err := con.e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := c.SubscribeTransactionStatus(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	con.tail = cancel
+	only := make(map[string]bool, len(args)-1)
+	for _, h := range args[1:] {
+		only[h] = true
+	}
+
+	go func() {
+		stream := client.NewTxStatusStream(s)
+		for {
+			upd, err := stream.Recv()
+			var amountErr *client.AmountError
+			switch {
+			case errors.As(err, &amountErr):
+				continue
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				fmt.Fprintln(con.ed, "tail stopped:", err)
+				return
+			}
+			if len(only) == 0 || only[upd.TxHash] {
+				fmt.Fprintln(con.ed, "tail:", formatStatus(newTxStatusView(upd)))
+			}
+		}
+	}()
+	return nil
+}
+
+func (con *console) stopTail() {
+	if con.tail != nil {
+		con.tail()
+		con.tail = nil
+	}
+}
+
+// complete returns the candidates for the last word of line.
+func (con *console) complete(line string) []string {
+	words := strings.Fields(line)
+	word := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		word, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	var cands []string
+	switch {
+	case len(words) == 0:
+		cands = append(sortedKeys(commands), consoleBuiltins...)
+	case len(words) == 1 && words[0] == "tail":
+		cands = []string{"on", "off"}
+	case len(words) == 1 && commands[words[0]] != nil && commands[words[0]].subs != nil:
+		cands = sortedKeys(commands[words[0]].subs)
+	case words[len(words)-1] == "-key":
+		cands = con.e.keystore.labels()
+	default:
+		for _, label := range con.e.keystore.labels() {
+			cands = append(cands, "@"+label, con.e.keystore[label].address)
+		}
+	}
+
+	matched := cands[:0]
+	for _, c := range cands {
+		if strings.HasPrefix(c, word) {
+			matched = append(matched, c)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+func (ks keystore) labels() []string {
+	labels := make([]string, 0, len(ks))
+	for l := range ks {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func sortedAddrs[V any](m map[string]V) []string {
+	addrs := make([]string, 0, len(m))
+	for a := range m {
+		addrs = append(addrs, a)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// splitLine splits a console line into words, honoring single and double quotes.
+func splitLine(line string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	var quote rune
+	inWord := false
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// lineEditor reads console lines. On a terminal it reads them with golang.org/x/term in
+// raw mode, with history, tab completion and output printed above the prompt; otherwise
+// it reads plain lines. It is also the console's output writer, so background output
+// never tears a line.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       int
+	term     *term.Terminal
+	complete func(line string) []string
+}
+
+func newLineEditor(in io.Reader, out io.Writer, complete func(string) []string) *lineEditor {
+	ed := &lineEditor{in: bufio.NewReader(in), out: out, complete: complete}
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		ed.fd = int(f.Fd())
+		ed.term = term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{f, out}, "")
+		ed.term.AutoCompleteCallback = ed.autoComplete
+	}
+	return ed
+}
+
+// Write prints p, above the prompt while a line is being edited.
+func (ed *lineEditor) Write(p []byte) (int, error) {
+	if ed.term == nil {
+		return ed.out.Write(p)
+	}
+	return ed.term.Write(p)
+}
+
+// ReadLine returns the next line without its newline, or io.EOF. On a terminal, Ctrl-D
+// on an empty line and Ctrl-C end the input.
+func (ed *lineEditor) ReadLine(prompt string) (string, error) {
+	if ed.term == nil {
+		io.WriteString(ed.out, prompt)
+		line, err := ed.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	state, err := term.MakeRaw(ed.fd)
+	if err != nil {
+		return "", fmt.Errorf("console: raw terminal mode: %w", err)
+	}
+	defer term.Restore(ed.fd, state)
+	if w, h, err := term.GetSize(ed.fd); err == nil && w > 0 {
+		ed.term.SetSize(w, h)
+	}
+	ed.term.SetPrompt(prompt)
+	return ed.term.ReadLine()
+}
+
+// autoComplete extends the word before the cursor on Tab, or lists the candidates when
+// they share nothing more.
+func (ed *lineEditor) autoComplete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' || ed.complete == nil {
+		return "", 0, false
+	}
+	head := line[:pos]
+	start := strings.LastIndex(head, " ") + 1
+	cands := ed.complete(head)
+	var word string
+	switch len(cands) {
+	case 0:
+		return "", 0, false
+	case 1:
+		word = cands[0] + " "
+	default:
+		word = commonPrefix(cands)
+		if len(word) <= len(head)-start {
+			fmt.Fprintln(ed.term, strings.Join(cands, "  "))
+			return "", 0, false
+		}
+	}
+	head = head[:start] + word
+	return head + line[pos:], len(head), true
+}
+
+// commonPrefix returns the longest run of whole runes that all of ss start with.
+func commonPrefix(ss []string) string {
+	prefix := []rune(ss[0])
+	for _, s := range ss[1:] {
+		r := []rune(s)
+		n := 0
+		for n < len(prefix) && n < len(r) && prefix[n] == r[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
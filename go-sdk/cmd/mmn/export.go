@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/export"
+)
+
+func runExport(e *env, args []string) error {
+	fs := e.flags("export -dir DIR [-format ndjson|csv|parquet] [-from SLOT] [-to SLOT] [-batch N] [-skip-errors]")
+	dir := fs.String("dir", "", "output directory; an export found there is resumed")
+	format := fs.String("format", string(export.FormatNDJSON), "ndjson, csv or parquet")
+	from := fs.Uint64("from", 0, "first slot")
+	to := fs.Uint64("to", 0, "last slot; the latest slot when 0")
+	batch := fs.Uint64("batch", 100, "slots per file")
+	skipErrors := fs.Bool("skip-errors", false, "export past slots the node reports errors for")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 0, false); err != nil || *dir == "" {
+		fs.Usage()
+		return errUsage
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+
+	res, err := export.Export(e.base, c, export.Options{
+		Dir:        *dir,
+		Format:     export.Format(*format),
+		From:       *from,
+		To:         *to,
+		BatchSize:  *batch,
+		SkipErrors: *skipErrors,
+		Progress: func(b export.Batch) error {
+			fmt.Fprintf(e.errOut, "slots %d-%d: %d blocks, %d transactions\n", b.From, b.To, b.Blocks, b.Transactions)
+			for _, msg := range b.Errors {
+				fmt.Fprintf(e.errOut, "slots %d-%d: skipped: %s\n", b.From, b.To, msg)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return e.print(res)
+}
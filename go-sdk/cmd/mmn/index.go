@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/indexer"
+)
+
+func runIndex(e *env, args []string) error {
+	fs := e.flags("index -db FILE [-listen ADDR] [-chain ID] [-poll DURATION] [-skip-errors]")
+	db := fs.String("db", "", "index database; created if missing, resumed otherwise")
+	listen := fs.String("listen", "", "serve the indexer HTTP API on this address")
+	chain := fs.String("chain", "", "decimal chain id reported in results and expected in API paths")
+	poll := fs.Duration("poll", time.Second, "interval between checks for new blocks")
+	skipErrors := fs.Bool("skip-errors", false, "index past slots the node reports errors for")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 0, false); err != nil || *db == "" {
+		fs.Usage()
+		return errUsage
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ix, err := indexer.Open(*db, c, indexer.Options{
+		ChainID:      *chain,
+		PollInterval: *poll,
+		SkipErrors:   *skipErrors,
+		OnError:      func(err error) { fmt.Fprintln(e.errOut, "mmn:", err) },
+	})
+	if err != nil {
+		return err
+	}
+	defer ix.Close()
+
+	if *listen != "" {
+		srv := &http.Server{Addr: *listen, Handler: ix.Handler()}
+		go func() {
+			<-e.base.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdown)
+		}()
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintln(e.errOut, "mmn:", err)
+				e.stop()
+			}
+		}()
+		fmt.Fprintf(e.errOut, "serving the indexer API on %s\n", *listen)
+	}
+
+	if err := ix.Run(e.base); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	next, err := ix.NextSlot()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.errOut, "stopped; indexing resumes at slot %d\n", next)
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mr-tron/base58"
+)
+
+// keyFile is the on-disk form of a key: the ed25519 seed in hex and its address.
+type keyFile struct {
+	Address string `json:"address"`
+	Seed    string `json:"seed"`
+}
+
+type key struct {
+	seed []byte
+}
+
+func (k key) public() ed25519.PublicKey {
+	return ed25519.NewKeyFromSeed(k.seed).Public().(ed25519.PublicKey)
+}
+
+func (k key) address() string {
+	return base58.Encode(k.public())
+}
+
+// parseKey accepts a seed or private key as hex, base58 or hex PKCS#8 DER.
+func parseKey(text string) (key, error) {
+	text = strings.TrimSpace(text)
+	raw, err := hex.DecodeString(text)
+	if err != nil {
+		if raw, err = base58.Decode(text); err != nil {
+			return key{}, errors.New("keys: key is neither hex nor base58")
+		}
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return key{seed: raw}, nil
+	case ed25519.PrivateKeySize:
+		return key{seed: raw[:ed25519.SeedSize]}, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(raw)
+	if err != nil {
+		return key{}, fmt.Errorf("keys: %d bytes is not a seed, private key or PKCS#8 key", len(raw))
+	}
+	priv, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return key{}, errors.New("keys: PKCS#8 key is not ed25519")
+	}
+	return key{seed: priv.Seed()}, nil
+}
+
+// loadKey reads a key file written by keys new or import, or a bare key as accepted by parseKey.
+func loadKey(path string) (key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return key{}, err
+	}
+	var f keyFile
+	if json.Unmarshal(data, &f) == nil && f.Seed != "" {
+		k, err := parseKey(f.Seed)
+		if err != nil {
+			return key{}, err
+		}
+		if f.Address != "" && f.Address != k.address() {
+			return key{}, fmt.Errorf("keys: %s: address does not match seed", path)
+		}
+		return k, nil
+	}
+	return parseKey(string(data))
+}
+
+// keystore maps labels, the file names without extension, to the key files of a directory.
+type keystore map[string]keystoreEntry
+
+type keystoreEntry struct {
+	path    string
+	address string
+}
+
+// loadKeystore reads the *.json key files of dir, skipping files that are not keys.
+// A missing directory is an empty keystore.
+func loadKeystore(dir string) (keystore, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	ks := make(keystore, len(paths))
+	for _, p := range paths {
+		k, err := loadKey(p)
+		if err != nil {
+			continue
+		}
+		ks[strings.TrimSuffix(filepath.Base(p), ".json")] = keystoreEntry{path: p, address: k.address()}
+	}
+	return ks, nil
+}
+
+// loadKey loads the key labeled name in the keystore, or the key file at path name.
+func (e *env) loadKey(name string) (key, error) {
+	if entry, ok := e.keystore[name]; ok {
+		return loadKey(entry.path)
+	}
+	return loadKey(name)
+}
+
+func saveKey(path string, k key) error {
+	data, err := json.MarshalIndent(keyFile{Address: k.address(), Seed: hex.EncodeToString(k.seed)}, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runKeysNew(e *env, args []string) error {
+	fs := e.flags("keys new -out FILE")
+	out := fs.String("out", "", "key file to create")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 0, false); err != nil || *out == "" {
+		fs.Usage()
+		return errUsage
+	}
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return err
+	}
+	k := key{seed: seed}
+	if err := saveKey(*out, k); err != nil {
+		return err
+	}
+	return e.print(map[string]string{"address": k.address(), "file": *out})
+}
+
+func runKeysImport(e *env, args []string) error {
+	fs := e.flags("keys import -out FILE [KEY]")
+	out := fs.String("out", "", "key file to create")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 || *out == "" {
+		fs.Usage()
+		return errUsage
+	}
+	text := fs.Arg(0)
+	if text == "" {
+		data, err := io.ReadAll(e.in)
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	}
+	k, err := parseKey(text)
+	if err != nil {
+		return err
+	}
+	if err := saveKey(*out, k); err != nil {
+		return err
+	}
+	return e.print(map[string]string{"address": k.address(), "file": *out})
+}
+
+func runKeysExport(e *env, args []string) error {
+	fs := e.flags("keys export -key FILE [-format hex|base58|pkcs8]")
+	path := fs.String("key", "", "key file")
+	format := fs.String("format", "hex", "hex or base58 seed, or hex PKCS#8 DER")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 0, false); err != nil || *path == "" {
+		fs.Usage()
+		return errUsage
+	}
+	k, err := e.loadKey(*path)
+	if err != nil {
+		return err
+	}
+
+	var encoded string
+	switch *format {
+	case "hex":
+		encoded = hex.EncodeToString(k.seed)
+	case "base58":
+		encoded = base58.Encode(k.seed)
+	case "pkcs8":
+		der, err := x509.MarshalPKCS8PrivateKey(ed25519.NewKeyFromSeed(k.seed))
+		if err != nil {
+			return err
+		}
+		encoded = hex.EncodeToString(der)
+	default:
+		return fmt.Errorf("keys: unknown format %q", *format)
+	}
+	return e.print(map[string]string{"address": k.address(), "format": *format, "private_key": encoded})
+}
+
+func runAddressFromUserID(e *env, args []string) error {
+	fs := e.flags("address from-user-id ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 1, false); err != nil {
+		return err
+	}
+	addr := client.AddressFromUserID(fs.Arg(0))
+	return e.print(map[string]any{"user_id": fs.Arg(0), "address": addr.String(), "reachable_by_key": addr.IsKey()})
+}
@@ -0,0 +1,214 @@
+// Command mmn talks to an mmn node through the Go SDK.
+//
+//	mmn [-endpoint HOST:PORT] [-tls] [-timeout 10s] COMMAND [ARGS]
+//
+// Commands:
+//
+//	health                          node health
+//	account ADDR...                 balance and nonce of accounts
+//	nonce [-tag pending] ADDR       current nonce
+//	tx get HASH                     transaction by hash
+//	tx send -key FILE -to ADDR -amount N [-text T] [-nonce N] [-wait]
+//	tx watch [HASH...]              stream status updates
+//	block get [SLOT...]             blocks by slot, latest by default
+//	block range FROM TO             block summaries of a slot range
+//	mempool                         pending transactions
+//	keys new|import|export          manage key files
+//	address from-user-id ID         address of a user ID
+//	export -dir DIR [-format F]     dump blocks and transactions to files
+//	console [-keys DIR]             interactive session, see console.go
+//
+// Every command prints JSON to stdout. The endpoint defaults to $MMN_ENDPOINT or localhost:9001.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+)
+
+var errUsage = errors.New("usage")
+
+// command is a leaf with run or a group with subs.
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+	subs  map[string]*command
+}
+
+func (c *command) dispatch(e *env, name string, args []string) error {
+	if c.subs == nil {
+		return c.run(e, args)
+	}
+	if len(args) == 0 || c.subs[args[0]] == nil {
+		fmt.Fprintf(e.errOut, "usage: %s %s\n", name, strings.Join(sortedKeys(c.subs), "|"))
+		return errUsage
+	}
+	return c.subs[args[0]].dispatch(e, name+" "+args[0], args[1:])
+}
+
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"health":  {usage: "health", run: runHealth},
+		"account": {usage: "account ADDR...", run: runAccount},
+		"nonce":   {usage: "nonce [-tag latest|pending] ADDR", run: runNonce},
+		"mempool": {usage: "mempool", run: runMempool},
+		"tx": {subs: map[string]*command{
+			"get":   {usage: "tx get HASH", run: runTxGet},
+			"send":  {usage: "tx send -key FILE -to ADDR -amount N [-text T] [-nonce N] [-wait]", run: runTxSend},
+			"watch": {usage: "tx watch [HASH...]", run: runTxWatch},
+		}},
+		"block": {subs: map[string]*command{
+			"get":   {usage: "block get [SLOT...]", run: runBlockGet},
+			"range": {usage: "block range FROM TO", run: runBlockRange},
+		}},
+		"keys": {subs: map[string]*command{
+			"new":    {usage: "keys new -out FILE", run: runKeysNew},
+			"import": {usage: "keys import -out FILE [KEY]", run: runKeysImport},
+			"export": {usage: "keys export -key FILE [-format hex|base58|pkcs8]", run: runKeysExport},
+		}},
+		"address": {subs: map[string]*command{
+			"from-user-id": {usage: "address from-user-id ID", run: runAddressFromUserID},
+		}},
+		"export":  {usage: "export -dir DIR [-format ndjson|csv|parquet] [-from SLOT] [-to SLOT] [-batch N] [-skip-errors]", run: runExport},
+		"index":   {usage: "index -db FILE [-listen ADDR] [-chain ID] [-poll DURATION] [-skip-errors]", run: runIndex},
+		"console": {usage: "console [-keys DIR]", run: runConsole},
+	}
+}
+
+// env is the state shared by commands: connection settings, the lazily dialed client and the output streams.
+// The console also sets pretty, the session nonce cache and the keystore.
+type env struct {
+	cfg     client.Config
+	timeout time.Duration
+	in      io.Reader
+	out     io.Writer
+	errOut  io.Writer
+	base    context.Context
+	stop    context.CancelFunc
+	c       client.MainnetClient
+
+	pretty   bool
+	nonces   map[string]sentTx
+	keystore keystore
+}
+
+// sentTx is the last transaction an address sent this session.
+type sentTx struct {
+	nonce uint64
+	hash  string
+}
+
+func (e *env) client() (client.MainnetClient, error) {
+	if e.c == nil {
+		c, err := client.NewClient(e.cfg)
+		if err != nil {
+			return nil, err
+		}
+		e.c = c
+	}
+	return e.c, nil
+}
+
+func (e *env) close() {
+	if e.c != nil {
+		e.c.Close()
+		e.c = nil
+	}
+}
+
+// ctx bounds a single request by the -timeout flag.
+func (e *env) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(e.base, e.timeout)
+}
+
+func (e *env) print(v any) error {
+	if e.pretty && printPretty(e.out, v) {
+		return nil
+	}
+	enc := json.NewEncoder(e.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// flags returns a flag set for a command that reports errors instead of exiting.
+func (e *env) flags(usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(usage, flag.ContinueOnError)
+	fs.SetOutput(e.errOut)
+	fs.Usage = func() {
+		fmt.Fprintf(e.errOut, "usage: %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// run executes one command line, without the global flags.
+func (e *env) run(args []string) error {
+	if len(args) == 0 || commands[args[0]] == nil {
+		fmt.Fprintf(e.errOut, "usage: mmn [flags] %s\n", strings.Join(sortedKeys(commands), "|"))
+		return errUsage
+	}
+	return commands[args[0]].dispatch(e, args[0], args[1:])
+}
+
+func main() {
+	endpoint := os.Getenv("MMN_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "localhost:9001"
+	}
+	flag.StringVar(&endpoint, "endpoint", endpoint, "node gRPC endpoint")
+	useTLS := flag.Bool("tls", false, "use TLS")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of each request")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	e := &env{
+		cfg:     client.Config{Endpoint: endpoint, UseTLS: *useTLS},
+		timeout: *timeout,
+		in:      os.Stdin,
+		out:     os.Stdout,
+		errOut:  os.Stderr,
+		base:    ctx,
+		stop:    stop,
+	}
+	err := e.run(flag.Args())
+	e.close()
+	switch {
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "mmn:", err)
+		os.Exit(1)
+	}
+}
+
+func sortedKeys(m map[string]*command) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// needArgs fails with the command usage unless fs has exactly n positional arguments, or at least n when atLeast is set.
+func needArgs(fs *flag.FlagSet, n int, atLeast bool) error {
+	if fs.NArg() == n || (atLeast && fs.NArg() > n) {
+		return nil
+	}
+	fs.Usage()
+	return errUsage
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mezonai/mmn-sdk/go-sdk/mmntest"
+)
+
+func newTestEnv(t *testing.T, n *mmntest.Node) (*env, *bytes.Buffer) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	e := &env{timeout: 5 * time.Second, out: &out, errOut: &errOut, base: context.Background()}
+	if n != nil {
+		c, err := n.Dial(client.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.c = c
+		t.Cleanup(e.close)
+	}
+	return e, &out
+}
+
+func runJSON(t *testing.T, e *env, out *bytes.Buffer, v any, args ...string) {
+	t.Helper()
+	out.Reset()
+	if err := e.run(args); err != nil {
+		t.Fatalf("mmn %s: %v", strings.Join(args, " "), err)
+	}
+	if err := json.Unmarshal(out.Bytes(), v); err != nil {
+		t.Fatalf("mmn %s: output %q: %v", strings.Join(args, " "), out, err)
+	}
+}
+
+func TestSendAndQuery(t *testing.T) {
+	n := mmntest.NewNode(mmntest.WithBlockInterval(100 * time.Millisecond))
+	defer n.Close()
+	e, out := newTestEnv(t, n)
+
+	keyPath := filepath.Join(t.TempDir(), "key.json")
+	var created map[string]string
+	runJSON(t, e, out, &created, "keys", "new", "-out", keyPath)
+	n.Fund(created["address"], uint256.NewInt(5_000_000))
+	recipient := client.GenerateAddress("bob")
+
+	out.Reset()
+	if err := e.run([]string{"tx", "send", "-key", keyPath, "-to", recipient, "-amount", "1.5", "-wait"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"status": "finalized"`) {
+		t.Fatalf("tx send -wait output = %s", out)
+	}
+
+	var acc accountView
+	runJSON(t, e, out, &acc, "account", recipient)
+	if acc.Balance != "1.5" || acc.Amount != "1500000" {
+		t.Errorf("account = %+v", acc)
+	}
+	var nonce struct{ Nonce uint64 }
+	runJSON(t, e, out, &nonce, "nonce", created["address"])
+	if nonce.Nonce != 1 {
+		t.Errorf("nonce = %d, want 1", nonce.Nonce)
+	}
+	var mempool mempoolView
+	runJSON(t, e, out, &mempool, "mempool")
+	if mempool.TotalCount != 0 {
+		t.Errorf("mempool = %+v", mempool)
+	}
+}
+
+func TestKeysRoundTrip(t *testing.T) {
+	e, out := newTestEnv(t, nil)
+	dir := t.TempDir()
+
+	var created, exported, imported map[string]string
+	runJSON(t, e, out, &created, "keys", "new", "-out", filepath.Join(dir, "a.json"))
+	runJSON(t, e, out, &exported, "keys", "export", "-key", filepath.Join(dir, "a.json"), "-format", "pkcs8")
+	runJSON(t, e, out, &imported, "keys", "import", "-out", filepath.Join(dir, "b.json"), exported["private_key"])
+	if imported["address"] != created["address"] {
+		t.Errorf("imported address %s, want %s", imported["address"], created["address"])
+	}
+	if err := e.run([]string{"keys", "new", "-out", filepath.Join(dir, "a.json")}); err == nil {
+		t.Error("keys new overwrote an existing file")
+	}
+	if err := e.run([]string{"block"}); err != errUsage {
+		t.Errorf("block without subcommand error = %v", err)
+	}
+}
+
+func TestNextNonce(t *testing.T) {
+	n := mmntest.NewNode(mmntest.WithAccount("sender", uint256.NewInt(0), 3))
+	defer n.Close()
+	e, _ := newTestEnv(t, n)
+	c, _ := e.client()
+	ctx := context.Background()
+
+	// The session sent nonce 5, but the node has never seen it: the entry is dropped
+	// instead of leaving a gap at 4 and 5.
+	e.nonces = map[string]sentTx{"sender": {nonce: 5, hash: "dropped"}}
+	if got, err := e.nextNonce(ctx, c, "sender"); err != nil || got != 4 {
+		t.Errorf("nextNonce() = %d, %v, want 4", got, err)
+	}
+	if _, ok := e.nonces["sender"]; ok {
+		t.Error("nextNonce() kept the unknown transaction")
+	}
+}
+
+func TestConsole(t *testing.T) {
+	// The node reports a pending nonce one behind from the second query on, so only
+	// the session nonce cache keeps the second send from reusing nonce 1.
+	s := mmntest.NewScenario()
+	s.On("GetCurrentNonce").After(1).StaleNonce(1)
+	n := mmntest.NewNode(mmntest.WithScenario(s))
+	defer n.Close()
+	e, out := newTestEnv(t, n)
+
+	dir := t.TempDir()
+	if err := e.run([]string{"keys", "new", "-out", filepath.Join(dir, "alice.json")}); err != nil {
+		t.Fatal(err)
+	}
+	ks, _ := loadKeystore(dir)
+	alice := ks["alice"].address
+	n.Fund(alice, uint256.NewInt(5_000_000))
+
+	out.Reset()
+	e.in = strings.NewReader(strings.Join([]string{
+		"health",
+		"tx send -key alice -to @alice -amount 1",
+		"tx send -key alice -to @alice -amount 1",
+		"nonces",
+		"account @nobody",
+		"exit",
+		"health",
+	}, "\n"))
+	if err := runConsole(e, []string{"-keys", dir}); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{"height", alice + "  2", `no key labeled "nobody"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("console output lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "nonce too low") || strings.Count(got, "height") != 1 {
+		t.Errorf("console output:\n%s", got)
+	}
+
+	con := &console{e: e}
+	for line, want := range map[string]string{
+		"tx s":                 "send",
+		"account @a":           "@alice",
+		"tx send -key ":        "alice",
+		"tail o":               "off on",
+		"account " + alice[:4]: alice,
+	} {
+		if got := strings.Join(con.complete(line), " "); got != want {
+			t.Errorf("complete(%q) = %q, want %q", line, got, want)
+		}
+	}
+	// é and è share their first UTF-8 byte, which must not be kept on its own.
+	if got := commonPrefix([]string{"@héa", "@hèb"}); got != "@h" {
+		t.Errorf("commonPrefix() = %q, want %q", got, "@h")
+	}
+}
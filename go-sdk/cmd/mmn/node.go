@@ -0,0 +1,198 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+)
+
+type accountView struct {
+	Address  string `json:"address"`
+	Balance  string `json:"balance"`
+	Amount   string `json:"amount"`
+	Nonce    uint64 `json:"nonce"`
+	Decimals uint32 `json:"decimals"`
+	Error    string `json:"error,omitempty"`
+}
+
+type mempoolView struct {
+	TotalCount   uint64                   `json:"total_count"`
+	Transactions []client.TransactionData `json:"transactions"`
+}
+
+type blockRangeView struct {
+	Blocks      []client.BlockInfo `json:"blocks"`
+	TotalBlocks uint32             `json:"total_blocks"`
+	Errors      []string           `json:"errors,omitempty"`
+	Decimals    uint32             `json:"decimals"`
+}
+
+func runHealth(e *env, args []string) error {
+	fs := e.flags("health")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 0, false); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	res, err := c.CheckHealth(ctx)
+	if err != nil {
+		return err
+	}
+	return e.print(res)
+}
+
+func runAccount(e *env, args []string) error {
+	fs := e.flags("account ADDR...")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 1, true); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	results, err := c.GetAccounts(ctx, fs.Args()...)
+	if err != nil {
+		return err
+	}
+
+	views := make([]accountView, len(results))
+	for i, r := range results {
+		views[i] = accountView{Address: r.Address}
+		if r.Err != nil {
+			views[i].Error = r.Err.Error()
+			continue
+		}
+		views[i].Balance = r.Account.FormatBalance()
+		views[i].Amount = r.Account.Balance.Dec()
+		views[i].Nonce = r.Account.Nonce
+		views[i].Decimals = r.Account.Decimals
+	}
+	if len(views) == 1 {
+		return e.print(views[0])
+	}
+	return e.print(views)
+}
+
+func runNonce(e *env, args []string) error {
+	fs := e.flags("nonce [-tag latest|pending] ADDR")
+	tag := fs.String("tag", "pending", "latest or pending")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 1, false); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	nonce, err := c.GetCurrentNonce(ctx, fs.Arg(0), *tag)
+	if err != nil {
+		return err
+	}
+	return e.print(map[string]any{"address": fs.Arg(0), "tag": *tag, "nonce": nonce})
+}
+
+func runMempool(e *env, args []string) error {
+	fs := e.flags("mempool")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 0, false); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	pending, err := c.GetPendingTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	return e.print(mempoolView{TotalCount: pending.TotalCount, Transactions: pending.Transactions})
+}
+
+func runBlockGet(e *env, args []string) error {
+	fs := e.flags("block get [SLOT...]")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	slots, err := parseSlots(fs.Args())
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	if len(slots) == 0 {
+		latest, err := c.GetBlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		slots = []uint64{latest}
+	}
+	blocks, err := c.GetBlockByNumber(ctx, slots...)
+	if err != nil {
+		return err
+	}
+	if len(blocks) == 1 {
+		return e.print(blocks[0])
+	}
+	return e.print(blocks)
+}
+
+func runBlockRange(e *env, args []string) error {
+	fs := e.flags("block range FROM TO")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 2, false); err != nil {
+		return err
+	}
+	slots, err := parseSlots(fs.Args())
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	r, err := c.GetBlockByRange(ctx, slots[0], slots[1])
+	if err != nil {
+		return err
+	}
+	return e.print(blockRangeView{Blocks: r.Blocks, TotalBlocks: r.TotalBlocks, Errors: r.Errors, Decimals: r.Decimals})
+}
+
+func parseSlots(args []string) ([]uint64, error) {
+	slots := make([]uint64, len(args))
+	for i, a := range args {
+		s, err := strconv.ParseUint(a, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		slots[i] = s
+	}
+	return slots, nil
+}
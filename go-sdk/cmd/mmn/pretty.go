@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+)
+
+// printPretty writes a human-readable form of the values the console shows most,
+// and reports false for anything else so the caller falls back to JSON.
+func printPretty(w io.Writer, v any) bool {
+	switch v := v.(type) {
+	case *mmnpb.HealthCheckResponse:
+		printHealth(w, v)
+	case client.Block:
+		printBlock(w, v.Slot, v.Hash, v.PrevHash, v.LeaderID, v.Timestamp, v.Transactions, v.Decimals)
+	case []client.Block:
+		for i, b := range v {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			printBlock(w, b.Slot, b.Hash, b.PrevHash, b.LeaderID, b.Timestamp, b.Transactions, b.Decimals)
+		}
+	case blockRangeView:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SLOT\tHASH\tLEADER\tTXS\tTIME")
+		for _, b := range v.Blocks {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\n", b.Slot, short(hex.EncodeToString(b.Hash)), short(b.LeaderID),
+				len(b.Transactions), formatTime(b.Timestamp))
+		}
+		tw.Flush()
+		for _, e := range v.Errors {
+			fmt.Fprintln(w, "error:", e)
+		}
+	case txStatusView:
+		fmt.Fprintln(w, formatStatus(v))
+	case client.TxInfo:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "hash\t%s\n", v.TxHash)
+		fmt.Fprintf(tw, "status\t%s\n", client.TxMeta_Status(v.Status))
+		fmt.Fprintf(tw, "from\t%s\n", v.Sender)
+		fmt.Fprintf(tw, "to\t%s\n", v.Recipient)
+		fmt.Fprintf(tw, "amount\t%s\n", v.FormatAmount())
+		fmt.Fprintf(tw, "nonce\t%d\n", v.Nonce)
+		fmt.Fprintf(tw, "slot\t%d\n", v.Slot)
+		fmt.Fprintf(tw, "time\t%s\n", formatTime(v.Timestamp))
+		if v.TextData != "" {
+			fmt.Fprintf(tw, "text\t%s\n", v.TextData)
+		}
+		if v.ErrMsg != "" {
+			fmt.Fprintf(tw, "error\t%s\n", v.ErrMsg)
+		}
+		tw.Flush()
+	default:
+		return false
+	}
+	return true
+}
+
+func printHealth(w io.Writer, h *mmnpb.HealthCheckResponse) {
+	role := "follower"
+	if h.IsLeader {
+		role = "leader"
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "status\t%s\n", strings.ToLower(h.Status.String()))
+	fmt.Fprintf(tw, "node\t%s (%s, %s)\n", h.NodeId, role, h.Version)
+	fmt.Fprintf(tw, "slot\t%d\n", h.CurrentSlot)
+	fmt.Fprintf(tw, "height\t%d\n", h.BlockHeight)
+	fmt.Fprintf(tw, "mempool\t%d\n", h.MempoolSize)
+	fmt.Fprintf(tw, "uptime\t%s\n", time.Duration(h.Uptime)*time.Second)
+	if h.ErrorMessage != "" {
+		fmt.Fprintf(tw, "error\t%s\n", h.ErrorMessage)
+	}
+	tw.Flush()
+}
+
+func printBlock(w io.Writer, slot uint64, hash, prev []byte, leader string, ts uint64, txs []client.TransactionData, decimals uint32) {
+	fmt.Fprintf(w, "block %d  %s\n", slot, hex.EncodeToString(hash))
+	fmt.Fprintf(w, "  prev    %s\n", hex.EncodeToString(prev))
+	fmt.Fprintf(w, "  leader  %s\n", leader)
+	fmt.Fprintf(w, "  time    %s\n", formatTime(ts))
+	if len(txs) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  HASH\tFROM\tTO\tAMOUNT\tNONCE\tSTATUS")
+	for _, tx := range txs {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%d\t%s\n", short(tx.TxHash), short(tx.Sender), short(tx.Recipient),
+			client.FormatAmount(tx.Amount, decimals), tx.Nonce, tx.Status)
+	}
+	tw.Flush()
+}
+
+func formatStatus(v txStatusView) string {
+	s := fmt.Sprintf("%-9s %s", v.Status, v.TxHash)
+	if v.BlockSlot > 0 {
+		s += fmt.Sprintf(" slot=%d", v.BlockSlot)
+	}
+	if v.Confirmations > 0 {
+		s += fmt.Sprintf(" confirmations=%d", v.Confirmations)
+	}
+	if v.Error != "" {
+		s += " error=" + v.Error
+	}
+	return s
+}
+
+func formatTime(ts uint64) string {
+	if ts == 0 {
+		return "-"
+	}
+	return time.Unix(int64(ts), 0).UTC().Format(time.RFC3339)
+}
+
+// short abbreviates long hashes and addresses for tables.
+func short(s string) string {
+	if len(s) <= 16 {
+		return s
+	}
+	return s[:8] + "…" + s[len(s)-6:]
+}
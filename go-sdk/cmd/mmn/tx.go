@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+)
+
+type txStatusView struct {
+	TxHash        string `json:"tx_hash"`
+	Status        string `json:"status"`
+	BlockSlot     uint64 `json:"block_slot,omitempty"`
+	BlockHash     string `json:"block_hash,omitempty"`
+	Confirmations uint64 `json:"confirmations,omitempty"`
+	Error         string `json:"error,omitempty"`
+	Sender        string `json:"sender,omitempty"`
+	Recipient     string `json:"recipient,omitempty"`
+	Amount        string `json:"amount,omitempty"`
+	Timestamp     uint64 `json:"timestamp,omitempty"`
+}
+
+func newTxStatusView(u client.TxStatusUpdate) txStatusView {
+	v := txStatusView{
+		TxHash:        u.TxHash,
+		Status:        u.Status.String(),
+		BlockSlot:     u.BlockSlot,
+		BlockHash:     u.BlockHash,
+		Confirmations: u.Confirmations,
+		Error:         u.ErrorMessage,
+		Sender:        u.Sender,
+		Recipient:     u.Recipient,
+		Timestamp:     u.Timestamp,
+	}
+	if u.Amount != nil {
+		v.Amount = u.Amount.Dec()
+	}
+	return v
+}
+
+func runTxGet(e *env, args []string) error {
+	fs := e.flags("tx get HASH")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 1, false); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	info, err := c.GetTxByHash(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return e.print(info)
+}
+
+func runTxSend(e *env, args []string) error {
+	fs := e.flags("tx send -key FILE -to ADDR -amount N [-text T] [-nonce N] [-wait]")
+	keyFile := fs.String("key", "", "key file of the sender")
+	to := fs.String("to", "", "recipient address")
+	amount := fs.String("amount", "", "amount in whole tokens, e.g. 1.5")
+	text := fs.String("text", "", "text data")
+	nonce := fs.Uint64("nonce", 0, "nonce; the pending nonce plus one when 0")
+	wait := fs.Bool("wait", false, "wait until the transaction is finalized or failed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := needArgs(fs, 0, false); err != nil {
+		return err
+	}
+	if *keyFile == "" || *to == "" || *amount == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	key, err := e.loadKey(*keyFile)
+	if err != nil {
+		return err
+	}
+	value, err := client.ParseAmount(*amount, client.NATIVE_DECIMAL)
+	if err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+
+	if *nonce == 0 {
+		if *nonce, err = e.nextNonce(ctx, c, key.address()); err != nil {
+			return err
+		}
+	}
+	tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, key.address(), *to, value, *nonce,
+		uint64(time.Now().Unix()), *text, nil, "", "")
+	if err != nil {
+		return err
+	}
+	signed, err := client.SignTx(tx, key.public(), key.seed)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before sending so no update of the new transaction is missed.
+	var stream *client.TxStatusStream
+	if *wait {
+		s, err := c.SubscribeTransactionStatus(e.base)
+		if err != nil {
+			return err
+		}
+		stream = client.NewTxStatusStream(s)
+	}
+	res, err := c.AddTx(ctx, signed)
+	if err != nil {
+		return err
+	}
+	if e.nonces != nil {
+		e.nonces[key.address()] = sentTx{nonce: tx.Nonce, hash: res.TxHash}
+	}
+	if stream == nil {
+		return e.print(map[string]any{"tx_hash": res.TxHash, "nonce": tx.Nonce})
+	}
+	return watch(e, stream, []string{res.TxHash})
+}
+
+// nextNonce returns the nonce after the node's pending nonce of addr, or after the
+// session's last transaction from addr when the node does not count that one yet. The
+// session's transaction is only trusted while the node knows it and it has not failed;
+// otherwise it is forgotten, so a dropped transaction leaves no nonce gap.
+func (e *env) nextNonce(ctx context.Context, c client.MainnetClient, addr string) (uint64, error) {
+	pending, err := c.GetCurrentNonce(ctx, addr, "pending")
+	if err != nil {
+		return 0, err
+	}
+	last, ok := e.nonces[addr]
+	if !ok || last.nonce <= pending {
+		return pending + 1, nil
+	}
+	info, err := c.GetTxByHash(ctx, last.hash)
+	switch {
+	case err == nil && client.TxMeta_Status(info.Status) != client.TxMeta_Status_FAILED:
+		return last.nonce + 1, nil
+	case err != nil && !errors.Is(err, client.ErrTxNotFound):
+		return 0, err
+	}
+	delete(e.nonces, addr)
+	return pending + 1, nil
+}
+
+func runTxWatch(e *env, args []string) error {
+	fs := e.flags("tx watch [HASH...]")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, err := e.client()
+	if err != nil {
+		return err
+	}
+	s, err := c.SubscribeTransactionStatus(e.base)
+	if err != nil {
+		return err
+	}
+	return watch(e, client.NewTxStatusStream(s), fs.Args())
+}
+
+// watch prints updates as JSON lines. With hashes it prints only theirs and returns once
+// each has finalized or failed; without, it runs until interrupted.
+func watch(e *env, stream *client.TxStatusStream, hashes []string) error {
+	open := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		open[h] = true
+	}
+	for {
+		upd, err := stream.Recv()
+		var amountErr *client.AmountError
+		switch {
+		case errors.As(err, &amountErr):
+			continue
+		case errors.Is(err, io.EOF), errors.Is(err, context.Canceled), e.base.Err() != nil:
+			return nil
+		case err != nil:
+			return err
+		}
+		if len(hashes) > 0 && !open[upd.TxHash] {
+			continue
+		}
+		if err := e.print(newTxStatusView(upd)); err != nil {
+			return err
+		}
+		if upd.Status == client.TxMeta_Status_FINALIZED || upd.Status == client.TxMeta_Status_FAILED {
+			delete(open, upd.TxHash)
+			if len(hashes) > 0 && len(open) == 0 {
+				return nil
+			}
+		}
+	}
+}
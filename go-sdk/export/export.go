@@ -0,0 +1,269 @@
+// Package export dumps blocks and transactions fetched with GetBlockByRange to
+// newline-delimited JSON, CSV or Parquet files.
+//
+// An export writes one pair of files per batch of slots into a directory,
+// blocks-FROM-TO.EXT and txs-FROM-TO.EXT, each written to a temporary file and renamed
+// into place. After each batch the directory's checkpoint file records the next slot, so
+// an interrupted export started again with the same directory resumes after the last
+// complete batch instead of starting over. A batch for which the node reports slot
+// errors is not stored unless Options.SkipErrors is set, so the checkpoint never moves
+// past a slot that was not exported.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is an output file format.
+type Format string
+
+const (
+	FormatNDJSON  Format = "ndjson"
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+// CheckpointFile is the name of the checkpoint file in the export directory.
+const CheckpointFile = "checkpoint.json"
+
+const defaultBatchSize = 100
+
+var (
+	ErrUnknownFormat      = errors.New("export: unknown format")
+	ErrInvalidRange       = errors.New("export: invalid slot range")
+	ErrCheckpointMismatch = errors.New("export: checkpoint belongs to another export")
+	ErrSlotErrors         = errors.New("export: node reported errors for slots")
+)
+
+type Options struct {
+	// Dir receives the files and the checkpoint. It is created if missing.
+	Dir    string
+	Format Format
+	// From and To are the inclusive slot range. To 0 means the latest slot when the export starts.
+	From, To uint64
+	// BatchSize is the number of slots per GetBlockByRange call and per file. Defaults to 100.
+	BatchSize uint64
+	// SkipErrors stores batches even when the node reports errors for some of their
+	// slots, such as slots without a block, and collects the errors in Batch.Errors and
+	// Result.Errors. Without it such a batch fails with ErrSlotErrors and is retried by
+	// the next run.
+	SkipErrors bool
+	// Progress, when set, is called after each batch is stored. Returning an error stops the export.
+	Progress func(Batch) error
+}
+
+// Batch describes a stored batch of slots.
+type Batch struct {
+	From, To     uint64
+	Blocks       int
+	Transactions int
+	// Errors are the per-slot errors the node reported, with Options.SkipErrors.
+	Errors []string
+	Files  []string
+}
+
+// Result sums up an export run. Resumed is set when it continued from a checkpoint.
+type Result struct {
+	From, To     uint64
+	Resumed      bool
+	Batches      int
+	Blocks       int
+	Transactions int
+	// Errors are the slot errors of all stored batches, with Options.SkipErrors.
+	Errors []string
+	Files  []string
+}
+
+type checkpoint struct {
+	Format   Format `json:"format"`
+	From     uint64 `json:"from"`
+	To       uint64 `json:"to"`
+	NextSlot uint64 `json:"next_slot"`
+}
+
+// Export writes the slot range of opts to files in opts.Dir, resuming from its checkpoint.
+// A checkpoint of a different format or start slot fails with ErrCheckpointMismatch.
+func Export(ctx context.Context, src client.BlockSource, opts Options) (Result, error) {
+	switch opts.Format {
+	case FormatNDJSON, FormatCSV, FormatParquet:
+	default:
+		return Result{}, fmt.Errorf("%w: %q", ErrUnknownFormat, opts.Format)
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return Result{}, err
+	}
+
+	cp, resumed, err := loadCheckpoint(filepath.Join(opts.Dir, CheckpointFile))
+	if err != nil {
+		return Result{}, err
+	}
+	if resumed {
+		if cp.Format != opts.Format || cp.From != opts.From || (opts.To != 0 && cp.To != opts.To) {
+			return Result{}, fmt.Errorf("%w: %s from slot %d to %d", ErrCheckpointMismatch, cp.Format, cp.From, cp.To)
+		}
+	} else {
+		to := opts.To
+		if to == 0 {
+			if to, err = src.GetBlockNumber(ctx); err != nil {
+				return Result{}, err
+			}
+		}
+		cp = checkpoint{Format: opts.Format, From: opts.From, To: to, NextSlot: opts.From}
+	}
+	if cp.From > cp.To {
+		return Result{}, fmt.Errorf("%w: %d to %d", ErrInvalidRange, cp.From, cp.To)
+	}
+
+	res := Result{From: cp.From, To: cp.To, Resumed: resumed}
+	for cp.NextSlot <= cp.To {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		from := cp.NextSlot
+		to := min(from+opts.BatchSize-1, cp.To)
+		batch, err := exportBatch(ctx, src, opts, from, to)
+		if err != nil {
+			return res, err
+		}
+
+		cp.NextSlot = to + 1
+		if err := writeFileAtomic(filepath.Join(opts.Dir, CheckpointFile), func(w io.Writer) error {
+			return json.NewEncoder(w).Encode(cp)
+		}); err != nil {
+			return res, err
+		}
+		res.Batches++
+		res.Blocks += batch.Blocks
+		res.Transactions += batch.Transactions
+		res.Errors = append(res.Errors, batch.Errors...)
+		res.Files = append(res.Files, batch.Files...)
+		if opts.Progress != nil {
+			if err := opts.Progress(batch); err != nil {
+				return res, err
+			}
+		}
+		if to == cp.To {
+			break
+		}
+	}
+	return res, nil
+}
+
+func exportBatch(ctx context.Context, src client.BlockSource, opts Options, from, to uint64) (Batch, error) {
+	r, err := src.GetBlockByRange(ctx, from, to)
+	if err != nil {
+		return Batch{}, fmt.Errorf("export: slots %d to %d: %w", from, to, err)
+	}
+	if len(r.Errors) > 0 && !opts.SkipErrors {
+		return Batch{}, fmt.Errorf("%w %d to %d: %s", ErrSlotErrors, from, to, strings.Join(r.Errors, "; "))
+	}
+
+	blocks := make([]BlockRow, 0, len(r.Blocks))
+	var txs []TxRow
+	for _, b := range r.Blocks {
+		blocks = append(blocks, blockRow(b))
+		for _, tx := range b.Transactions {
+			txs = append(txs, txRow(b, tx))
+		}
+	}
+
+	batch := Batch{From: from, To: to, Blocks: len(blocks), Transactions: len(txs), Errors: r.Errors}
+	for _, f := range []struct {
+		prefix string
+		write  func(io.Writer) error
+	}{
+		{"blocks", func(w io.Writer) error { return writeRows(w, opts.Format, blocks) }},
+		{"txs", func(w io.Writer) error { return writeRows(w, opts.Format, txs) }},
+	} {
+		path := filepath.Join(opts.Dir, fmt.Sprintf("%s-%d-%d.%s", f.prefix, from, to, opts.Format))
+		if err := writeFileAtomic(path, f.write); err != nil {
+			return Batch{}, err
+		}
+		batch.Files = append(batch.Files, path)
+	}
+	return batch, nil
+}
+
+type csvRow interface {
+	csvHeader() []string
+	csvRecord() []string
+}
+
+func writeRows[T csvRow](w io.Writer, format Format, rows []T) error {
+	switch format {
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, r := range rows {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		var zero T
+		if err := cw.Write(zero.csvHeader()); err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if err := cw.Write(r.csvRecord()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatParquet:
+		return parquet.Write(w, rows)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+func loadCheckpoint(path string) (checkpoint, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint{}, false, nil
+	}
+	if err != nil {
+		return checkpoint{}, false, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return checkpoint{}, false, fmt.Errorf("export: checkpoint %s: %w", path, err)
+	}
+	return cp, true, nil
+}
+
+// writeFileAtomic writes path through a synced temporary file renamed into place.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
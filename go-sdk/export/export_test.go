@@ -0,0 +1,184 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mezonai/mmn-sdk/go-sdk/mmntest"
+	"github.com/mr-tron/base58"
+	"github.com/parquet-go/parquet-go"
+)
+
+// newChain returns a node with slots 0 to 5, holding three transfers in slots 2 and 4.
+func newChain(t *testing.T) *client.MmnClient {
+	t.Helper()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	sender := base58.Encode(pub)
+	n := mmntest.NewNode(mmntest.WithAccount(sender, uint256.NewInt(1000), 0))
+	t.Cleanup(n.Close)
+	c, err := n.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	send := func(nonce uint64) {
+		tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, sender, client.GenerateAddress("bob"), uint256.NewInt(10),
+			nonce, uint64(time.Now().Unix()), "lunch", map[string]string{"type": client.TransactionExtraInfoGiveCoffee}, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, _ := client.SignTx(tx, pub, priv.Seed())
+		if _, err := c.AddTx(context.Background(), signed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n.ProduceBlock()
+	send(1)
+	send(2)
+	n.ProduceBlock()
+	n.ProduceBlock()
+	send(3)
+	n.ProduceBlock()
+	n.ProduceBlock()
+	return c
+}
+
+func readNDJSON[T any](t *testing.T, paths ...string) []T {
+	t.Helper()
+	var rows []T
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var r T
+			if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+				t.Fatal(err)
+			}
+			rows = append(rows, r)
+		}
+		f.Close()
+	}
+	return rows
+}
+
+func TestExport_ResumeAfterInterruption(t *testing.T) {
+	c := newChain(t)
+	dir := t.TempDir()
+	stop := errors.New("stop")
+	opts := Options{Dir: dir, Format: FormatNDJSON, BatchSize: 2}
+
+	interrupted := opts
+	interrupted.Progress = func(b Batch) error {
+		if b.To >= 1 {
+			return stop
+		}
+		return nil
+	}
+	first, err := Export(context.Background(), c, interrupted)
+	if !errors.Is(err, stop) || first.Batches != 1 || first.To != 5 {
+		t.Fatalf("interrupted Export() = %+v, %v", first, err)
+	}
+
+	second, err := Export(context.Background(), c, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Resumed || second.Batches != 2 || second.Transactions != 3 {
+		t.Fatalf("resumed Export() = %+v", second)
+	}
+
+	blocks, _ := filepath.Glob(filepath.Join(dir, "blocks-*.ndjson"))
+	txFiles, _ := filepath.Glob(filepath.Join(dir, "txs-*.ndjson"))
+	if got := readNDJSON[BlockRow](t, blocks...); len(got) != 6 {
+		t.Errorf("exported %d blocks, want 6", len(got))
+	}
+	txs := readNDJSON[TxRow](t, txFiles...)
+	if len(txs) != 3 {
+		t.Fatalf("exported %d transactions, want 3", len(txs))
+	}
+	tx := txs[0]
+	if tx.Slot != 2 || tx.Amount != "10" || tx.Status != "finalized" || tx.TextData != "lunch" ||
+		tx.ExtraType != client.TransactionExtraInfoGiveCoffee || tx.Extra["type"] != tx.ExtraType {
+		t.Errorf("first transaction row = %+v", tx)
+	}
+
+	done, err := Export(context.Background(), c, opts)
+	if err != nil || done.Batches != 0 {
+		t.Errorf("Export() of finished range = %+v, %v", done, err)
+	}
+	opts.Format = FormatCSV
+	if _, err := Export(context.Background(), c, opts); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("Export() with another format error = %v", err)
+	}
+}
+
+func TestExport_SlotErrors(t *testing.T) {
+	c := newChain(t)
+	// Slots 6 and 7 have no block yet.
+	opts := Options{Dir: t.TempDir(), Format: FormatNDJSON, BatchSize: 2, To: 7}
+	res, err := Export(context.Background(), c, opts)
+	if !errors.Is(err, ErrSlotErrors) || res.Batches != 3 {
+		t.Fatalf("Export() = %+v, %v, want ErrSlotErrors after 3 batches", res, err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(opts.Dir, "*-6-7.ndjson")); len(files) != 0 {
+		t.Errorf("failed batch wrote %v", files)
+	}
+
+	opts.SkipErrors = true
+	res, err = Export(context.Background(), c, opts)
+	if err != nil || !res.Resumed || res.Batches != 1 || len(res.Errors) != 2 {
+		t.Errorf("Export() skipping errors = %+v, %v", res, err)
+	}
+}
+
+func TestExport_CSVAndParquet(t *testing.T) {
+	c := newChain(t)
+
+	csvDir := t.TempDir()
+	if _, err := Export(context.Background(), c, Options{Dir: csvDir, Format: FormatCSV, From: 2, To: 4}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(csvDir, "txs-2-4.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(f).ReadAll()
+	f.Close()
+	if err != nil || len(records) != 4 || records[0][0] != "slot" || records[3][13] != `{"type":"give-coffee"}` {
+		t.Errorf("csv records = %q, %v", records, err)
+	}
+
+	pqDir := t.TempDir()
+	res, err := Export(context.Background(), c, Options{Dir: pqDir, Format: FormatParquet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var txs []TxRow
+	for _, p := range res.Files {
+		if filepath.Base(p)[:3] != "txs" {
+			continue
+		}
+		rows, err := parquet.ReadFile[TxRow](p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, rows...)
+	}
+	if len(txs) != 3 || txs[2].Slot != 4 || txs[2].Nonce != 3 || txs[2].Extra["type"] != client.TransactionExtraInfoGiveCoffee {
+		t.Errorf("parquet transactions = %+v", txs)
+	}
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+)
+
+// BlockRow is one exported block.
+type BlockRow struct {
+	Slot      uint64 `json:"slot" parquet:"slot"`
+	Hash      string `json:"hash" parquet:"hash"`
+	PrevHash  string `json:"prev_hash" parquet:"prev_hash"`
+	LeaderID  string `json:"leader_id" parquet:"leader_id"`
+	Timestamp uint64 `json:"timestamp" parquet:"timestamp"`
+	TxCount   int    `json:"tx_count" parquet:"tx_count"`
+}
+
+// TxRow is one exported transaction, flattened with its block and with extra_info and,
+// for user content, text_data decoded into columns. The raw fields are kept as well.
+type TxRow struct {
+	Slot      uint64 `json:"slot" parquet:"slot"`
+	BlockHash string `json:"block_hash" parquet:"block_hash"`
+	TxHash    string `json:"tx_hash" parquet:"tx_hash"`
+	Type      int    `json:"type" parquet:"type"`
+	Sender    string `json:"sender" parquet:"sender"`
+	Recipient string `json:"recipient" parquet:"recipient"`
+	// Amount is the amount in base units as a decimal string.
+	Amount    string `json:"amount" parquet:"amount"`
+	Nonce     uint64 `json:"nonce" parquet:"nonce"`
+	Timestamp uint64 `json:"timestamp" parquet:"timestamp"`
+	Status    string `json:"status" parquet:"status"`
+	TextData  string `json:"text_data" parquet:"text_data"`
+	ExtraInfo string `json:"extra_info" parquet:"extra_info"`
+
+	ExtraType string            `json:"extra_type,omitempty" parquet:"extra_type"`
+	Extra     map[string]string `json:"extra,omitempty" parquet:"extra"`
+
+	ContentType        string   `json:"content_type,omitempty" parquet:"content_type"`
+	ContentTitle       string   `json:"content_title,omitempty" parquet:"content_title"`
+	ContentDescription string   `json:"content_description,omitempty" parquet:"content_description"`
+	ContentImageCIDs   []string `json:"content_image_cids,omitempty" parquet:"content_image_cids,list"`
+	ContentParentHash  string   `json:"content_parent_hash,omitempty" parquet:"content_parent_hash"`
+	ContentRootHash    string   `json:"content_root_hash,omitempty" parquet:"content_root_hash"`
+}
+
+func blockRow(b client.BlockInfo) BlockRow {
+	return BlockRow{
+		Slot:      b.Slot,
+		Hash:      hex.EncodeToString(b.Hash),
+		PrevHash:  hex.EncodeToString(b.PrevHash),
+		LeaderID:  b.LeaderID,
+		Timestamp: b.Timestamp,
+		TxCount:   len(b.Transactions),
+	}
+}
+
+// txRow flattens tx. Undecodable extra_info or text_data is left in the raw columns only.
+func txRow(b client.BlockInfo, tx client.TransactionData) TxRow {
+	r := TxRow{
+		Slot:      b.Slot,
+		BlockHash: hex.EncodeToString(b.Hash),
+		TxHash:    tx.TxHash,
+		Type:      tx.Type,
+		Sender:    tx.Sender,
+		Recipient: tx.Recipient,
+		Amount:    client.Uint256ToString(tx.Amount),
+		Nonce:     tx.Nonce,
+		Timestamp: tx.Timestamp,
+		Status:    tx.Status.String(),
+		TextData:  tx.TextData,
+		ExtraInfo: tx.ExtraInfo,
+	}
+	if extra, err := client.DeserializeTxExtraInfo(tx.ExtraInfo); err == nil && len(extra) > 0 {
+		r.Extra, r.ExtraType = extra, extra["type"]
+	}
+	if tx.Type == client.TxTypeUserContent {
+		var c client.UserContent
+		if json.Unmarshal([]byte(tx.TextData), &c) == nil {
+			r.ContentType, r.ContentTitle, r.ContentDescription = c.Type, c.Title, c.Description
+			r.ContentImageCIDs, r.ContentParentHash, r.ContentRootHash = c.ImageCIDs, c.ParentHash, c.RootHash
+		}
+	}
+	return r
+}
+
+func (BlockRow) csvHeader() []string {
+	return []string{"slot", "hash", "prev_hash", "leader_id", "timestamp", "tx_count"}
+}
+
+func (r BlockRow) csvRecord() []string {
+	return []string{u64(r.Slot), r.Hash, r.PrevHash, r.LeaderID, u64(r.Timestamp), strconv.Itoa(r.TxCount)}
+}
+
+func (TxRow) csvHeader() []string {
+	return []string{"slot", "block_hash", "tx_hash", "type", "sender", "recipient", "amount", "nonce", "timestamp",
+		"status", "text_data", "extra_info", "extra_type", "extra", "content_type", "content_title",
+		"content_description", "content_image_cids", "content_parent_hash", "content_root_hash"}
+}
+
+// csvRecord renders Extra as a JSON object and the image CIDs separated by semicolons.
+func (r TxRow) csvRecord() []string {
+	extra := ""
+	if len(r.Extra) > 0 {
+		b, _ := json.Marshal(r.Extra)
+		extra = string(b)
+	}
+	return []string{u64(r.Slot), r.BlockHash, r.TxHash, strconv.Itoa(r.Type), r.Sender, r.Recipient, r.Amount,
+		u64(r.Nonce), u64(r.Timestamp), r.Status, r.TextData, r.ExtraInfo, r.ExtraType, extra, r.ContentType,
+		r.ContentTitle, r.ContentDescription, strings.Join(r.ContentImageCIDs, ";"), r.ContentParentHash, r.ContentRootHash}
+}
+
+func u64(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
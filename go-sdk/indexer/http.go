@@ -0,0 +1,142 @@
+package indexer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// Handler serves the indexer over the HTTP API the JS IndexerClient calls, so an
+// IndexerClient pointed at it needs no remote indexer. Responses have the JS shapes;
+// transactions leave out the EVM fields (gas, r, s, v, ...) the chain does not have, and
+// wallet details add the sent and received counts and totals:
+//
+//	GET /{chain}/tx/{hash}/detail
+//	GET /{chain}/transactions?wallet_address|filter_from_address|filter_to_address=...&page&limit&sort_order
+//	GET /{chain}/transactions/infinite?wallet_address|...&limit&timestamp_lt&last_hash
+//	GET /{chain}/wallets?page&limit
+//	GET /{chain}/wallets/{address}/detail
+func (ix *Indexer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{chain}/tx/{hash}/detail", ix.serveTx)
+	mux.HandleFunc("GET /{chain}/transactions", ix.serveTransactions)
+	mux.HandleFunc("GET /{chain}/transactions/infinite", ix.serveInfinite)
+	mux.HandleFunc("GET /{chain}/wallets", ix.serveWallets)
+	mux.HandleFunc("GET /{chain}/wallets/{address}/detail", ix.serveWallet)
+	return mux
+}
+
+func (ix *Indexer) serveTx(w http.ResponseWriter, r *http.Request) {
+	if !ix.checkChain(w, r) {
+		return
+	}
+	t, err := ix.TransactionByHash(r.PathValue("hash"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, map[string]any{"data": map[string]any{"transaction": t}})
+}
+
+func (ix *Indexer) serveTransactions(w http.ResponseWriter, r *http.Request) {
+	if !ix.checkChain(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	wallet, filter := walletFilter(q.Get)
+	page, _ := strconv.Atoi(q.Get("page"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	list, err := ix.TransactionsByWallet(wallet, filter, page, limit, q.Get("sort_order") == "asc")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+func (ix *Indexer) serveInfinite(w http.ResponseWriter, r *http.Request) {
+	if !ix.checkChain(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	wallet, filter := walletFilter(q.Get)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	cur := Cursor{LastHash: q.Get("last_hash")}
+	if ts := q.Get("timestamp_lt"); ts != "" {
+		var err error
+		if cur.TimestampLT, err = strconv.ParseUint(ts, 10, 64); err != nil {
+			http.Error(w, "invalid timestamp_lt", http.StatusBadRequest)
+			return
+		}
+	}
+	list, err := ix.TransactionsBefore(wallet, filter, limit, cur)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, list)
+}
+
+func (ix *Indexer) serveWallets(w http.ResponseWriter, r *http.Request) {
+	if !ix.checkChain(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	addrs, err := ix.Wallets(page, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, map[string]any{"meta": Meta{ChainID: ix.chainID, Page: max(page, 0), Limit: clampLimit(limit, defaultPageLimit)}, "data": addrs})
+}
+
+func (ix *Indexer) serveWallet(w http.ResponseWriter, r *http.Request) {
+	if !ix.checkChain(w, r) {
+		return
+	}
+	d, err := ix.WalletDetail(r.Context(), r.PathValue("address"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, map[string]any{"data": d})
+}
+
+// checkChain rejects requests for another chain when Options.ChainID is set.
+func (ix *Indexer) checkChain(w http.ResponseWriter, r *http.Request) bool {
+	if ix.opts.ChainID != "" && r.PathValue("chain") != ix.opts.ChainID {
+		http.Error(w, "unknown chain", http.StatusNotFound)
+		return false
+	}
+	return true
+}
+
+// walletFilter maps the JS client's query parameters to a wallet and Filter.
+func walletFilter(get func(string) string) (string, Filter) {
+	if v := get("filter_from_address"); v != "" {
+		return v, FilterSent
+	}
+	if v := get("filter_to_address"); v != "" {
+		return v, FilterReceived
+	}
+	return get("wallet_address"), FilterAll
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidQuery):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
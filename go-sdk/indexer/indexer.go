@@ -0,0 +1,506 @@
+// Package indexer is an embedded replacement for the remote indexer API. It follows
+// blocks with GetBlockNumber and GetBlockByRange, stores their transactions in a bbolt
+// database and answers the queries of the JS IndexerClient: a transaction by hash, a
+// wallet's transactions by page or by before-timestamp cursor, and wallet summaries.
+//
+// Each indexed block range is committed in one database transaction together with the
+// next slot to fetch, so a restarted indexer continues where it stopped. Transactions
+// indexed before they were final are looked up again on every Sync until they are
+// finalized or failed; one the node no longer knows is recorded as failed after a few
+// Syncs. Wallet totals only count finalized transactions.
+package indexer
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	defaultBatchSize    = 100
+	defaultPollInterval = time.Second
+	defaultDroppedAfter = 3
+)
+
+var (
+	ErrNotFound     = errors.New("indexer: not found")
+	ErrInvalidQuery = errors.New("indexer: invalid query")
+	ErrSlotErrors   = errors.New("indexer: node reported errors for slots")
+)
+
+var (
+	bucketMeta    = []byte("meta")
+	bucketTxs     = []byte("txs")
+	bucketWallet  = []byte("wallet_txs")
+	bucketSummary = []byte("wallets")
+	bucketOpen    = []byte("unsettled")
+	keyNextSlot   = []byte("next_slot")
+)
+
+// Source is the part of client.MainnetClient the indexer needs: blocks, transaction
+// lookups for statuses that were not final yet, and accounts for wallet balances.
+type Source interface {
+	client.BlockSource
+	GetTxByHash(ctx context.Context, txHash string) (client.TxInfo, error)
+	GetAccount(ctx context.Context, addr string) (client.Account, error)
+}
+
+type Options struct {
+	// ChainID is the decimal chain id, reported in transactions and list metadata and
+	// expected in API paths. Empty accepts any chain in paths.
+	ChainID string
+	// BatchSize is the number of slots per GetBlockByRange call. Defaults to 100.
+	BatchSize uint64
+	// PollInterval is how long Run waits for new blocks once caught up. Defaults to 1s.
+	PollInterval time.Duration
+	// SkipErrors indexes past slots the node reports errors for. Without it Sync stops
+	// before the first such slot that has no block, returns ErrSlotErrors and fetches
+	// the slot again on the next call.
+	SkipErrors bool
+	// DroppedAfter is the number of Syncs in a row that cannot find a transaction that
+	// was not final before it is recorded as failed. Defaults to 3.
+	DroppedAfter int
+	// OnError receives errors looking up transactions that were not final; Sync skips
+	// those transactions until its next call. Optional.
+	OnError func(error)
+}
+
+// Indexer indexes the blocks of a Source into a bbolt database.
+type Indexer struct {
+	db      *bolt.DB
+	src     Source
+	opts    Options
+	chainID uint64
+}
+
+// Open opens or creates the database at path. Indexing starts at slot 0, or at the slot
+// after the last one indexed into an existing database.
+func Open(path string, src Source, opts Options) (*Indexer, error) {
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.DroppedAfter <= 0 {
+		opts.DroppedAfter = defaultDroppedAfter
+	}
+	var chainID uint64
+	if opts.ChainID != "" {
+		var err error
+		if chainID, err = strconv.ParseUint(opts.ChainID, 10, 64); err != nil {
+			return nil, fmt.Errorf("indexer: chain id %q is not a decimal number", opts.ChainID)
+		}
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("indexer: open %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketMeta, bucketTxs, bucketWallet, bucketSummary, bucketOpen} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Indexer{db: db, src: src, opts: opts, chainID: chainID}, nil
+}
+
+func (ix *Indexer) Close() error {
+	return ix.db.Close()
+}
+
+// NextSlot returns the first slot not indexed yet.
+func (ix *Indexer) NextSlot() (uint64, error) {
+	var next uint64
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		next = nextSlot(tx)
+		return nil
+	})
+	return next, err
+}
+
+// Sync indexes all blocks up to the node's current slot, updates the statuses of
+// transactions that were not final yet and returns the next slot.
+func (ix *Indexer) Sync(ctx context.Context) (uint64, error) {
+	latest, err := ix.src.GetBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	next, err := ix.NextSlot()
+	if err != nil {
+		return 0, err
+	}
+	for next <= latest {
+		if err := ctx.Err(); err != nil {
+			return next, err
+		}
+		to := min(next+ix.opts.BatchSize-1, latest)
+		r, err := ix.src.GetBlockByRange(ctx, next, to)
+		if err != nil {
+			return next, fmt.Errorf("indexer: slots %d to %d: %w", next, to, err)
+		}
+		end := to + 1
+		if len(r.Errors) > 0 && !ix.opts.SkipErrors {
+			end = firstMissing(r.Blocks, next, to)
+		}
+		if err := ix.db.Update(func(tx *bolt.Tx) error {
+			for _, b := range r.Blocks {
+				if b.Slot >= end {
+					continue
+				}
+				if err := ix.indexBlock(tx, b); err != nil {
+					return err
+				}
+			}
+			return putNextSlot(tx, end)
+		}); err != nil {
+			return next, err
+		}
+		if end <= to {
+			return end, fmt.Errorf("%w %d to %d, stopped at slot %d: %s", ErrSlotErrors, next, to, end, strings.Join(r.Errors, "; "))
+		}
+		next = end
+	}
+	return next, ix.settle(ctx)
+}
+
+// firstMissing returns the first slot from from to to without a block in blocks, or
+// to+1 if none is missing.
+func firstMissing(blocks []client.BlockInfo, from, to uint64) uint64 {
+	have := make(map[uint64]bool, len(blocks))
+	for _, b := range blocks {
+		have[b.Slot] = true
+	}
+	for slot := from; slot <= to; slot++ {
+		if !have[slot] {
+			return slot
+		}
+	}
+	return to + 1
+}
+
+// settle looks up the transactions indexed before they were final and records those
+// that are now finalized or failed. The unsettled bucket maps each hash to the number of
+// lookups in a row that did not find it; at Options.DroppedAfter the transaction is
+// recorded as failed. Lookup errors go to Options.OnError.
+func (ix *Indexer) settle(ctx context.Context) error {
+	misses := make(map[string]uint64)
+	if err := ix.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketOpen).ForEach(func(k, v []byte) error {
+			misses[string(k)] = decodeMisses(v)
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+	for hash, n := range misses {
+		info, err := ix.src.GetTxByHash(ctx, hash)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var update func(*bolt.Tx) error
+		switch {
+		case errors.Is(err, client.ErrTxNotFound):
+			if n+1 >= uint64(ix.opts.DroppedAfter) {
+				update = func(tx *bolt.Tx) error { return setStatus(tx, hash, client.TxMeta_Status_FAILED) }
+			} else {
+				update = func(tx *bolt.Tx) error { return putMisses(tx, hash, n+1) }
+			}
+		case err != nil:
+			ix.reportError(fmt.Errorf("indexer: transaction %s: %w", hash, err))
+			continue
+		case final(client.TxMeta_Status(info.Status)):
+			update = func(tx *bolt.Tx) error { return setStatus(tx, hash, client.TxMeta_Status(info.Status)) }
+		case n > 0:
+			update = func(tx *bolt.Tx) error { return putMisses(tx, hash, 0) }
+		default:
+			continue
+		}
+		if err := ix.db.Update(update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ix *Indexer) reportError(err error) {
+	if ix.opts.OnError != nil {
+		ix.opts.OnError(err)
+	}
+}
+
+// Run calls Sync every PollInterval until ctx is done. Retryable node errors and
+// ErrSlotErrors are retried on the next round; other errors end the run.
+func (ix *Indexer) Run(ctx context.Context) error {
+	t := time.NewTicker(ix.opts.PollInterval)
+	defer t.Stop()
+	for {
+		_, err := ix.Sync(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !client.IsRetryable(err) && !errors.Is(err, ErrSlotErrors) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Transaction is an indexed transaction, shaped like the JS IndexerClient's Transaction.
+type Transaction struct {
+	ChainID              string               `json:"chain_id"`
+	Hash                 string               `json:"hash"`
+	Nonce                uint64               `json:"nonce"`
+	BlockHash            string               `json:"block_hash"`
+	BlockNumber          uint64               `json:"block_number"`
+	BlockTimestamp       uint64               `json:"block_timestamp"`
+	TransactionIndex     int                  `json:"transaction_index"`
+	FromAddress          string               `json:"from_address"`
+	ToAddress            string               `json:"to_address"`
+	Value                string               `json:"value"`
+	TransactionType      int                  `json:"transaction_type"`
+	Status               client.TxMeta_Status `json:"status"`
+	TransactionTimestamp uint64               `json:"transaction_timestamp"`
+	TextData             string               `json:"text_data"`
+	ExtraInfo            string               `json:"extra_info"`
+}
+
+// WalletDetail is the JS client's WalletDetail with a summary of the wallet's indexed
+// transactions. Balance and AccountNonce come from the node when the detail is read.
+// Amounts are decimal strings in base units; the totals count finalized transactions
+// only.
+type WalletDetail struct {
+	Address           string `json:"address"`
+	Balance           string `json:"balance"`
+	AccountNonce      uint64 `json:"account_nonce"`
+	SentCount         uint64 `json:"sent_count"`
+	ReceivedCount     uint64 `json:"received_count"`
+	TotalSent         string `json:"total_sent"`
+	TotalReceived     string `json:"total_received"`
+	FirstSeen         uint64 `json:"first_seen"`
+	LastBalanceUpdate uint64 `json:"last_balance_update"`
+}
+
+// Wallet index values flag which side of a transaction the wallet is on.
+const (
+	sideSent byte = 1 << iota
+	sideReceived
+)
+
+// final reports whether a transaction with status s can no longer change.
+func final(s client.TxMeta_Status) bool {
+	return s == client.TxMeta_Status_FINALIZED || s == client.TxMeta_Status_FAILED
+}
+
+func (ix *Indexer) indexBlock(tx *bolt.Tx, b client.BlockInfo) error {
+	txs := tx.Bucket(bucketTxs)
+	for i, td := range b.Transactions {
+		if txs.Get([]byte(td.TxHash)) != nil {
+			// Seen in an earlier block; only a newer final status matters.
+			if final(td.Status) {
+				if err := setStatus(tx, td.TxHash, td.Status); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		t := Transaction{
+			ChainID:              ix.opts.ChainID,
+			Hash:                 td.TxHash,
+			Nonce:                td.Nonce,
+			BlockHash:            hex.EncodeToString(b.Hash),
+			BlockNumber:          b.Slot,
+			BlockTimestamp:       b.Timestamp,
+			TransactionIndex:     i,
+			FromAddress:          td.Sender,
+			ToAddress:            td.Recipient,
+			Value:                client.Uint256ToString(td.Amount),
+			TransactionType:      td.Type,
+			Status:               td.Status,
+			TransactionTimestamp: td.Timestamp,
+			TextData:             td.TextData,
+			ExtraInfo:            td.ExtraInfo,
+		}
+		data, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if err := txs.Put([]byte(t.Hash), data); err != nil {
+			return err
+		}
+		if !final(t.Status) {
+			if err := tx.Bucket(bucketOpen).Put([]byte(t.Hash), binary.BigEndian.AppendUint64(nil, 0)); err != nil {
+				return err
+			}
+		}
+
+		sides := map[string]byte{}
+		sides[t.FromAddress] |= sideSent
+		sides[t.ToAddress] |= sideReceived
+		for addr, side := range sides {
+			if addr == "" {
+				continue
+			}
+			if err := tx.Bucket(bucketWallet).Put(walletKey(addr, t.TransactionTimestamp, t.Hash), []byte{side}); err != nil {
+				return err
+			}
+			if err := updateSummary(tx, addr, func(d *WalletDetail) {
+				if d.FirstSeen == 0 || t.TransactionTimestamp < d.FirstSeen {
+					d.FirstSeen = t.TransactionTimestamp
+				}
+				if side&sideSent != 0 {
+					d.SentCount++
+				}
+				if side&sideReceived != 0 {
+					d.ReceivedCount++
+				}
+				if t.Status == client.TxMeta_Status_FINALIZED {
+					addTotals(d, side, t)
+				}
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setStatus records the final status of an indexed transaction that was not final and
+// counts it in the wallet totals if it was finalized.
+func setStatus(tx *bolt.Tx, hash string, status client.TxMeta_Status) error {
+	if tx.Bucket(bucketOpen).Get([]byte(hash)) == nil {
+		return nil
+	}
+	var t Transaction
+	if err := json.Unmarshal(tx.Bucket(bucketTxs).Get([]byte(hash)), &t); err != nil {
+		return fmt.Errorf("indexer: transaction %s: %w", hash, err)
+	}
+	t.Status = status
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketTxs).Put([]byte(hash), data); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucketOpen).Delete([]byte(hash)); err != nil {
+		return err
+	}
+	if status != client.TxMeta_Status_FINALIZED {
+		return nil
+	}
+	sides := map[string]byte{}
+	sides[t.FromAddress] |= sideSent
+	sides[t.ToAddress] |= sideReceived
+	for addr, side := range sides {
+		if addr == "" {
+			continue
+		}
+		if err := updateSummary(tx, addr, func(d *WalletDetail) { addTotals(d, side, t) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateSummary applies fn to the stored summary of addr.
+func updateSummary(tx *bolt.Tx, addr string, fn func(*WalletDetail)) error {
+	bucket := tx.Bucket(bucketSummary)
+	d := WalletDetail{Address: addr, TotalSent: "0", TotalReceived: "0"}
+	if data := bucket.Get([]byte(addr)); data != nil {
+		if err := json.Unmarshal(data, &d); err != nil {
+			return fmt.Errorf("indexer: wallet %s: %w", addr, err)
+		}
+	}
+	fn(&d)
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(addr), data)
+}
+
+// addTotals counts the finalized transaction t on the given sides of a wallet.
+func addTotals(d *WalletDetail, side byte, t Transaction) {
+	amount, err := uint256.FromDecimal(t.Value)
+	if err != nil {
+		return
+	}
+	if side&sideSent != 0 {
+		d.TotalSent = addDecimal(d.TotalSent, amount)
+	}
+	if side&sideReceived != 0 {
+		d.TotalReceived = addDecimal(d.TotalReceived, amount)
+	}
+	d.LastBalanceUpdate = max(d.LastBalanceUpdate, t.TransactionTimestamp)
+}
+
+func addDecimal(total string, amount *uint256.Int) string {
+	sum, err := uint256.FromDecimal(total)
+	if err != nil {
+		sum = new(uint256.Int)
+	}
+	return sum.Add(sum, amount).Dec()
+}
+
+// walletKey orders a wallet's transactions by timestamp, then hash:
+// address, 0x00, big-endian timestamp, hash.
+func walletKey(addr string, ts uint64, hash string) []byte {
+	k := make([]byte, 0, len(addr)+9+len(hash))
+	k = append(k, addr...)
+	k = append(k, 0)
+	k = binary.BigEndian.AppendUint64(k, ts)
+	return append(k, hash...)
+}
+
+func walletPrefix(addr string) []byte {
+	return append([]byte(addr), 0)
+}
+
+func decodeMisses(v []byte) uint64 {
+	if len(v) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+// putMisses stores the miss count of an unsettled transaction, if it is still unsettled.
+func putMisses(tx *bolt.Tx, hash string, n uint64) error {
+	bucket := tx.Bucket(bucketOpen)
+	if bucket.Get([]byte(hash)) == nil {
+		return nil
+	}
+	return bucket.Put([]byte(hash), binary.BigEndian.AppendUint64(nil, n))
+}
+
+func nextSlot(tx *bolt.Tx) uint64 {
+	v := tx.Bucket(bucketMeta).Get(keyNextSlot)
+	if len(v) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+func putNextSlot(tx *bolt.Tx, slot uint64) error {
+	return tx.Bucket(bucketMeta).Put(keyNextSlot, binary.BigEndian.AppendUint64(nil, slot))
+}
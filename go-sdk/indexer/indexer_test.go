@@ -0,0 +1,315 @@
+package indexer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mezonai/mmn-sdk/go-sdk/mmntest"
+	"github.com/mr-tron/base58"
+)
+
+type chain struct {
+	node   *mmntest.Node
+	c      *client.MmnClient
+	alice  string
+	bob    string
+	hashes []string
+}
+
+// newChain returns a node where alice sends bob four transfers, at timestamps 100 to 103,
+// and bob sends one back at 104, spread over two blocks.
+func newChain(t *testing.T) *chain {
+	t.Helper()
+	alicePub, alicePriv, _ := ed25519.GenerateKey(nil)
+	bobPub, bobPriv, _ := ed25519.GenerateKey(nil)
+	ch := &chain{alice: base58.Encode(alicePub), bob: base58.Encode(bobPub)}
+	ch.node = mmntest.NewNode(
+		mmntest.WithAccount(ch.alice, uint256.NewInt(1000), 0),
+		mmntest.WithAccount(ch.bob, uint256.NewInt(0), 0),
+	)
+	t.Cleanup(ch.node.Close)
+	c, err := ch.node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	ch.c = c
+
+	send := func(pub ed25519.PublicKey, priv ed25519.PrivateKey, to string, amount, nonce, ts uint64) {
+		tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, base58.Encode(pub), to, uint256.NewInt(amount),
+			nonce, ts, "", nil, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, _ := client.SignTx(tx, pub, priv.Seed())
+		res, err := c.AddTx(context.Background(), signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch.hashes = append(ch.hashes, res.TxHash)
+	}
+	for i := range uint64(3) {
+		send(alicePub, alicePriv, ch.bob, 10, i+1, 100+i)
+	}
+	ch.node.ProduceBlock()
+	send(alicePub, alicePriv, ch.bob, 10, 4, 103)
+	send(bobPub, bobPriv, ch.alice, 5, 1, 104)
+	ch.node.ProduceBlock()
+	return ch
+}
+
+func TestIndexer_Queries(t *testing.T) {
+	ch := newChain(t)
+	path := filepath.Join(t.TempDir(), "index.db")
+	ix, err := Open(path, ch.c, Options{ChainID: "1337", BatchSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, err := ix.Sync(context.Background()); err != nil || next != 3 {
+		t.Fatalf("Sync() = %d, %v", next, err)
+	}
+
+	tx, err := ix.TransactionByHash(ch.hashes[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.BlockNumber != 2 || tx.TransactionIndex != 0 || tx.FromAddress != ch.alice || tx.Value != "10" ||
+		tx.Status != client.TxMeta_Status_FINALIZED || tx.ChainID != "1337" {
+		t.Errorf("TransactionByHash() = %+v", tx)
+	}
+	if _, err := ix.TransactionByHash("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("TransactionByHash(missing) error = %v", err)
+	}
+
+	for filter, want := range map[Filter]int{FilterAll: 5, FilterSent: 4, FilterReceived: 1} {
+		list, err := ix.TransactionsByWallet(ch.alice, filter, 0, 2, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if list.Meta.TotalItems != want || len(list.Data) != min(want, 2) || list.Meta.HasMore != (want > 2) {
+			t.Errorf("filter %d: meta = %+v, %d items", filter, list.Meta, len(list.Data))
+		}
+	}
+	asc, _ := ix.TransactionsByWallet(ch.alice, FilterAll, 0, 0, true)
+	if asc.Data[0].TransactionTimestamp != 100 || asc.Data[4].TransactionTimestamp != 104 {
+		t.Errorf("ascending order = %+v", asc.Data)
+	}
+
+	// Paging with the cursor visits every transaction once, newest first.
+	var seen []uint64
+	cur := Cursor{}
+	for {
+		list, err := ix.TransactionsBefore(ch.alice, FilterAll, 2, cur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tx := range list.Data {
+			seen = append(seen, tx.TransactionTimestamp)
+		}
+		if !list.Meta.HasMore {
+			break
+		}
+		ts, err := strconv.ParseUint(list.Meta.NextTimestamp, 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cur = Cursor{TimestampLT: ts, LastHash: list.Meta.NextHash}
+	}
+	if len(seen) != 5 || seen[0] != 104 || seen[4] != 100 {
+		t.Errorf("cursor pages visited %v", seen)
+	}
+
+	d, err := ix.WalletDetail(context.Background(), ch.alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.SentCount != 4 || d.ReceivedCount != 1 || d.TotalSent != "40" || d.TotalReceived != "5" ||
+		d.Balance != "965" || d.AccountNonce != 4 || d.FirstSeen != 100 || d.LastBalanceUpdate != 104 {
+		t.Errorf("WalletDetail() = %+v", d)
+	}
+
+	// A reopened index resumes after the last indexed slot without counting twice.
+	ix.Close()
+	ch.node.ProduceBlock()
+	if ix, err = Open(path, ch.c, Options{ChainID: "1337"}); err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+	if next, err := ix.Sync(context.Background()); err != nil || next != 4 {
+		t.Fatalf("resumed Sync() = %d, %v", next, err)
+	}
+	if d, _ := ix.WalletDetail(context.Background(), ch.bob); d.ReceivedCount != 4 || d.SentCount != 1 {
+		t.Errorf("WalletDetail(bob) after resume = %+v", d)
+	}
+	if addrs, _ := ix.Wallets(0, 0); len(addrs) != 2 {
+		t.Errorf("Wallets() = %v", addrs)
+	}
+}
+
+func TestIndexer_Handler(t *testing.T) {
+	ch := newChain(t)
+	ix, err := Open(filepath.Join(t.TempDir(), "index.db"), ch.c, Options{ChainID: "1337"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+	if _, err := ix.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(ix.Handler())
+	defer srv.Close()
+
+	get := func(path string, v any) int {
+		t.Helper()
+		res, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if v != nil && res.StatusCode == 200 {
+			if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return res.StatusCode
+	}
+
+	var detail struct {
+		Data struct{ Transaction Transaction } `json:"data"`
+	}
+	if code := get("/1337/tx/"+ch.hashes[4]+"/detail", &detail); code != 200 || detail.Data.Transaction.FromAddress != ch.bob {
+		t.Errorf("tx detail = %d, %+v", code, detail)
+	}
+	var list TransactionList
+	if code := get("/1337/transactions/infinite?limit=3&filter_to_address="+ch.bob, &list); code != 200 ||
+		len(list.Data) != 3 || !list.Meta.HasMore || list.Meta.NextTimestamp != "101" || list.Meta.ChainID != 1337 {
+		t.Errorf("infinite = %d, %+v", code, list.Meta)
+	}
+	var wallet struct{ Data WalletDetail }
+	if code := get("/1337/wallets/"+ch.bob+"/detail", &wallet); code != 200 || wallet.Data.TotalReceived != "40" || wallet.Data.Balance != "35" {
+		t.Errorf("wallet detail = %d, %+v", code, wallet.Data)
+	}
+	if code := get("/other/wallets/"+ch.bob+"/detail", nil); code != 404 {
+		t.Errorf("other chain status = %d", code)
+	}
+	if code := get("/1337/transactions", nil); code != 400 {
+		t.Errorf("transactions without wallet status = %d", code)
+	}
+}
+
+// lateSource reports every transaction as confirmed until final is set, and leaves out
+// the block of slot missing with a slot error until it is cleared. Lookups of the hashes
+// in errs fail with their error.
+type lateSource struct {
+	Source
+	final   bool
+	missing uint64
+	errs    map[string]error
+}
+
+func (s *lateSource) GetBlockByRange(ctx context.Context, from, to uint64) (client.BlockRange, error) {
+	r, err := s.Source.GetBlockByRange(ctx, from, to)
+	var blocks []client.BlockInfo
+	for _, b := range r.Blocks {
+		if b.Slot == s.missing {
+			r.Errors = append(r.Errors, "block not found")
+			continue
+		}
+		for i := range b.Transactions {
+			if !s.final {
+				b.Transactions[i].Status = client.TxMeta_Status_CONFIRMED
+			}
+		}
+		blocks = append(blocks, b)
+	}
+	r.Blocks = blocks
+	return r, err
+}
+
+func (s *lateSource) GetTxByHash(ctx context.Context, hash string) (client.TxInfo, error) {
+	if err := s.errs[hash]; err != nil {
+		return client.TxInfo{}, err
+	}
+	info, err := s.Source.GetTxByHash(ctx, hash)
+	if !s.final {
+		info.Status = int32(client.TxMeta_Status_CONFIRMED)
+	}
+	return info, err
+}
+
+func TestIndexer_LateFinalityAndSlotErrors(t *testing.T) {
+	ch := newChain(t)
+	src := &lateSource{Source: ch.c, missing: 2}
+	ix, err := Open(filepath.Join(t.TempDir(), "index.db"), src, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+	ctx := context.Background()
+
+	// Slot 2 is retried instead of skipped.
+	if next, err := ix.Sync(ctx); !errors.Is(err, ErrSlotErrors) || next != 2 {
+		t.Fatalf("Sync() with a slot error = %d, %v", next, err)
+	}
+	src.missing = 0
+	if next, err := ix.Sync(ctx); err != nil || next != 3 {
+		t.Fatalf("Sync() = %d, %v", next, err)
+	}
+	if tx, _ := ix.TransactionByHash(ch.hashes[4]); tx.BlockNumber != 2 || tx.Status != client.TxMeta_Status_CONFIRMED {
+		t.Errorf("transaction of the retried slot = %+v", tx)
+	}
+	if d, _ := ix.WalletDetail(ctx, ch.alice); d.SentCount != 4 || d.TotalSent != "0" {
+		t.Errorf("WalletDetail() before finality = %+v", d)
+	}
+
+	// Once final, the statuses and totals catch up without new blocks.
+	src.final = true
+	if _, err := ix.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tx, _ := ix.TransactionByHash(ch.hashes[0]); tx.Status != client.TxMeta_Status_FINALIZED {
+		t.Errorf("transaction after finality = %+v", tx)
+	}
+	if d, _ := ix.WalletDetail(ctx, ch.alice); d.SentCount != 4 || d.TotalSent != "40" || d.TotalReceived != "5" {
+		t.Errorf("WalletDetail() after finality = %+v", d)
+	}
+}
+
+func TestIndexer_DroppedAndLookupErrors(t *testing.T) {
+	ch := newChain(t)
+	broken := errors.New("broken lookup")
+	src := &lateSource{Source: ch.c, missing: 99, errs: map[string]error{ch.hashes[0]: client.ErrTxNotFound, ch.hashes[1]: broken}}
+	var reported []error
+	ix, err := Open(filepath.Join(t.TempDir(), "index.db"), src, Options{OnError: func(err error) { reported = append(reported, err) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+
+	for i := 1; i <= defaultDroppedAfter; i++ {
+		if _, err := ix.Sync(context.Background()); err != nil {
+			t.Fatalf("Sync() %d = %v", i, err)
+		}
+		want := client.TxMeta_Status_CONFIRMED
+		if i == defaultDroppedAfter {
+			want = client.TxMeta_Status_FAILED
+		}
+		if tx, _ := ix.TransactionByHash(ch.hashes[0]); tx.Status != want {
+			t.Errorf("dropped transaction after %d syncs = %s, want %s", i, tx.Status, want)
+		}
+	}
+	if tx, _ := ix.TransactionByHash(ch.hashes[1]); tx.Status != client.TxMeta_Status_CONFIRMED {
+		t.Errorf("transaction with a broken lookup = %+v", tx)
+	}
+	if len(reported) != defaultDroppedAfter || !errors.Is(reported[0], broken) {
+		t.Errorf("OnError got %v", reported)
+	}
+}
@@ -0,0 +1,286 @@
+package indexer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Filter selects a wallet's transactions by side. The values match the JS client.
+type Filter int
+
+const (
+	FilterAll      Filter = 0
+	FilterReceived Filter = 1
+	FilterSent     Filter = 2
+)
+
+const (
+	defaultPageLimit   = 50
+	defaultCursorLimit = 20
+	maxLimit           = 1000
+)
+
+// Meta describes a page of results, like the JS client's Meta. Page is 0-based.
+// NextTimestamp and NextHash are the cursor for the next TransactionsBefore call.
+type Meta struct {
+	ChainID       uint64 `json:"chain_id"`
+	Address       string `json:"address,omitempty"`
+	Page          int    `json:"page"`
+	Limit         int    `json:"limit,omitempty"`
+	TotalItems    int    `json:"total_items,omitempty"`
+	TotalPages    int    `json:"total_pages,omitempty"`
+	HasMore       bool   `json:"has_more"`
+	NextTimestamp string `json:"next_timestamp,omitempty"`
+	NextHash      string `json:"next_hash,omitempty"`
+}
+
+// TransactionList is a page of transactions, like the JS client's ListTransactionResponse.
+type TransactionList struct {
+	Meta Meta          `json:"meta"`
+	Data []Transaction `json:"data"`
+}
+
+// Cursor positions TransactionsBefore. The zero Cursor starts at the newest transaction.
+type Cursor struct {
+	// TimestampLT returns transactions older than this timestamp; 0 means no bound.
+	TimestampLT uint64
+	// LastHash, with TimestampLT, also returns transactions at TimestampLT whose hash
+	// sorts before it, so a page boundary inside one timestamp loses nothing.
+	LastHash string
+}
+
+// TransactionByHash returns the indexed transaction with the given hash or ErrNotFound.
+func (ix *Indexer) TransactionByHash(hash string) (Transaction, error) {
+	var t Transaction
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketTxs).Get([]byte(hash))
+		if data == nil {
+			return fmt.Errorf("%w: transaction %s", ErrNotFound, hash)
+		}
+		return json.Unmarshal(data, &t)
+	})
+	return t, err
+}
+
+// TransactionsByWallet returns page (0-based) of the wallet's transactions ordered by
+// transaction timestamp, newest first unless ascending. limit defaults to 50 and is
+// capped at 1000.
+func (ix *Indexer) TransactionsByWallet(wallet string, filter Filter, page, limit int, ascending bool) (TransactionList, error) {
+	if err := checkQuery(wallet, filter); err != nil {
+		return TransactionList{}, err
+	}
+	page = max(page, 0)
+	limit = clampLimit(limit, defaultPageLimit)
+
+	list := TransactionList{Meta: Meta{ChainID: ix.chainID, Address: wallet, Page: page, Limit: limit}}
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		var hashes []string
+		err := scanWallet(tx, wallet, filter, ascending, nil, func(hash string) bool {
+			hashes = append(hashes, hash)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		list.Meta.TotalItems = len(hashes)
+		list.Meta.TotalPages = (len(hashes) + limit - 1) / limit
+		start := min(page*limit, len(hashes))
+		end := min(start+limit, len(hashes))
+		list.Meta.HasMore = end < len(hashes)
+		list.Data, err = loadTxs(tx, hashes[start:end])
+		return err
+	})
+	return list, err
+}
+
+// TransactionsBefore returns up to limit of the wallet's transactions after cur, newest
+// first. limit defaults to 20 and is capped at 1000. When Meta.HasMore is set,
+// Meta.NextTimestamp and Meta.NextHash hold the cursor for the following page.
+func (ix *Indexer) TransactionsBefore(wallet string, filter Filter, limit int, cur Cursor) (TransactionList, error) {
+	if err := checkQuery(wallet, filter); err != nil {
+		return TransactionList{}, err
+	}
+	limit = clampLimit(limit, defaultCursorLimit)
+
+	var before []byte
+	if cur.TimestampLT != 0 {
+		before = walletKey(wallet, cur.TimestampLT, cur.LastHash)
+	}
+	list := TransactionList{Meta: Meta{ChainID: ix.chainID, Address: wallet, Limit: limit}}
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		var hashes []string
+		err := scanWallet(tx, wallet, filter, false, before, func(hash string) bool {
+			if len(hashes) == limit {
+				list.Meta.HasMore = true
+				return false
+			}
+			hashes = append(hashes, hash)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		list.Data, err = loadTxs(tx, hashes)
+		return err
+	})
+	if err == nil && list.Meta.HasMore {
+		last := list.Data[len(list.Data)-1]
+		list.Meta.NextTimestamp = strconv.FormatUint(last.TransactionTimestamp, 10)
+		list.Meta.NextHash = last.Hash
+	}
+	return list, err
+}
+
+// WalletDetail returns the wallet's balance and nonce from the node with the summary
+// of its indexed transactions. It returns ErrNotFound if the node has no such account
+// and no transaction of the wallet was indexed.
+func (ix *Indexer) WalletDetail(ctx context.Context, wallet string) (WalletDetail, error) {
+	d := WalletDetail{Address: wallet, TotalSent: "0", TotalReceived: "0"}
+	indexed := false
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketSummary).Get([]byte(wallet))
+		if data == nil {
+			return nil
+		}
+		indexed = true
+		return json.Unmarshal(data, &d)
+	})
+	if err != nil {
+		return WalletDetail{}, err
+	}
+
+	acc, err := ix.src.GetAccount(ctx, wallet)
+	switch {
+	case errors.Is(err, client.ErrAccountNotFound) && !indexed:
+		return WalletDetail{}, fmt.Errorf("%w: wallet %s", ErrNotFound, wallet)
+	case errors.Is(err, client.ErrAccountNotFound):
+		d.Balance = "0"
+	case err != nil:
+		return WalletDetail{}, fmt.Errorf("indexer: wallet %s: %w", wallet, err)
+	default:
+		d.Balance, d.AccountNonce = client.Uint256ToString(acc.Balance), acc.Nonce
+	}
+	return d, nil
+}
+
+// Wallets returns page (0-based) of the indexed wallet addresses in address order.
+func (ix *Indexer) Wallets(page, limit int) ([]string, error) {
+	page = max(page, 0)
+	limit = clampLimit(limit, defaultPageLimit)
+	var addrs []string
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		skip := page * limit
+		c := tx.Bucket(bucketSummary).Cursor()
+		for k, _ := c.First(); k != nil && len(addrs) < limit; k, _ = c.Next() {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			addrs = append(addrs, string(k))
+		}
+		return nil
+	})
+	return addrs, err
+}
+
+func checkQuery(wallet string, filter Filter) error {
+	if wallet == "" {
+		return fmt.Errorf("%w: wallet address cannot be empty", ErrInvalidQuery)
+	}
+	if filter < FilterAll || filter > FilterSent {
+		return fmt.Errorf("%w: filter %d", ErrInvalidQuery, filter)
+	}
+	return nil
+}
+
+func clampLimit(limit, def int) int {
+	if limit <= 0 {
+		return def
+	}
+	return min(limit, maxLimit)
+}
+
+// scanWallet calls fn with the hashes of the wallet's transactions matching filter,
+// in timestamp order, until fn returns false. A non-nil before key starts the
+// descending scan just below it.
+func scanWallet(tx *bolt.Tx, wallet string, filter Filter, ascending bool, before []byte, fn func(hash string) bool) error {
+	prefix := walletPrefix(wallet)
+	c := tx.Bucket(bucketWallet).Cursor()
+
+	var k, v []byte
+	switch {
+	case ascending:
+		k, v = c.Seek(prefix)
+	case before != nil:
+		k, v = c.Seek(before)
+		if k == nil {
+			k, v = c.Last()
+		}
+		for k != nil && bytes.Compare(k, before) >= 0 {
+			k, v = c.Prev()
+		}
+	default:
+		// The key after the prefix range is the prefix with its 0x00 separator bumped.
+		end := append(bytes.Clone(prefix[:len(prefix)-1]), 1)
+		k, v = c.Seek(end)
+		if k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+	}
+
+	for ; k != nil && bytes.HasPrefix(k, prefix); k, v = step(c, ascending) {
+		if len(v) != 1 || len(k) < len(prefix)+8 {
+			return fmt.Errorf("indexer: corrupt wallet index entry %q", k)
+		}
+		if !matches(v[0], filter) {
+			continue
+		}
+		if !fn(string(k[len(prefix)+8:])) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func step(c *bolt.Cursor, ascending bool) ([]byte, []byte) {
+	if ascending {
+		return c.Next()
+	}
+	return c.Prev()
+}
+
+func matches(side byte, filter Filter) bool {
+	switch filter {
+	case FilterSent:
+		return side&sideSent != 0
+	case FilterReceived:
+		return side&sideReceived != 0
+	}
+	return true
+}
+
+func loadTxs(tx *bolt.Tx, hashes []string) ([]Transaction, error) {
+	txs := make([]Transaction, 0, len(hashes))
+	b := tx.Bucket(bucketTxs)
+	for _, h := range hashes {
+		var t Transaction
+		data := b.Get([]byte(h))
+		if data == nil {
+			return nil, fmt.Errorf("indexer: wallet index references missing transaction %s", h)
+		}
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, err
+		}
+		txs = append(txs, t)
+	}
+	return txs, nil
+}
@@ -0,0 +1,141 @@
+package mmntest
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"google.golang.org/grpc"
+)
+
+var ErrNotMocked = errors.New("mmntest: method not mocked")
+
+// MockClient is a client.MainnetClient whose methods call the matching func field.
+// Methods whose field is nil return ErrNotMocked.
+type MockClient struct {
+	AddTxFunc                      func(ctx context.Context, tx client.SignedTx) (client.AddTxResponse, error)
+	AddTxBatchFunc                 func(ctx context.Context, txs []client.SignedTx, opts client.BatchOptions) ([]client.BatchItemResult, error)
+	SimulateTxFunc                 func(ctx context.Context, tx client.SignedTx) (client.SimulationResult, error)
+	GetAccountFunc                 func(ctx context.Context, addr string) (client.Account, error)
+	GetAccountsFunc                func(ctx context.Context, addrs ...string) ([]client.AccountResult, error)
+	SubscribeTransactionStatusFunc func(ctx context.Context) (mmnpb.TxService_SubscribeTransactionStatusClient, error)
+	GetTxByHashFunc                func(ctx context.Context, txHash string) (client.TxInfo, error)
+	GetPendingTransactionsFunc     func(ctx context.Context) (client.PendingTransactions, error)
+	CheckHealthFunc                func(ctx context.Context) (*mmnpb.HealthCheckResponse, error)
+	GetCurrentNonceFunc            func(ctx context.Context, addr string, tag string) (uint64, error)
+	GetBlockNumberFunc             func(ctx context.Context) (uint64, error)
+	GetBlockByNumberFunc           func(ctx context.Context, blockNumbers ...uint64) ([]client.Block, error)
+	GetBlockByRangeFunc            func(ctx context.Context, fromSlot, toSlot uint64) (client.BlockRange, error)
+	ConnFunc                       func() *grpc.ClientConn
+	CloseFunc                      func() error
+}
+
+var _ client.MainnetClient = (*MockClient)(nil)
+
+func (m *MockClient) AddTx(ctx context.Context, tx client.SignedTx) (client.AddTxResponse, error) {
+	if m.AddTxFunc == nil {
+		return client.AddTxResponse{}, ErrNotMocked
+	}
+	return m.AddTxFunc(ctx, tx)
+}
+
+func (m *MockClient) AddTxBatch(ctx context.Context, txs []client.SignedTx, opts client.BatchOptions) ([]client.BatchItemResult, error) {
+	if m.AddTxBatchFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.AddTxBatchFunc(ctx, txs, opts)
+}
+
+func (m *MockClient) SimulateTx(ctx context.Context, tx client.SignedTx) (client.SimulationResult, error) {
+	if m.SimulateTxFunc == nil {
+		return client.SimulationResult{}, ErrNotMocked
+	}
+	return m.SimulateTxFunc(ctx, tx)
+}
+
+func (m *MockClient) GetAccount(ctx context.Context, addr string) (client.Account, error) {
+	if m.GetAccountFunc == nil {
+		return client.Account{}, ErrNotMocked
+	}
+	return m.GetAccountFunc(ctx, addr)
+}
+
+func (m *MockClient) GetAccounts(ctx context.Context, addrs ...string) ([]client.AccountResult, error) {
+	if m.GetAccountsFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.GetAccountsFunc(ctx, addrs...)
+}
+
+func (m *MockClient) SubscribeTransactionStatus(ctx context.Context) (mmnpb.TxService_SubscribeTransactionStatusClient, error) {
+	if m.SubscribeTransactionStatusFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.SubscribeTransactionStatusFunc(ctx)
+}
+
+func (m *MockClient) GetTxByHash(ctx context.Context, txHash string) (client.TxInfo, error) {
+	if m.GetTxByHashFunc == nil {
+		return client.TxInfo{}, ErrNotMocked
+	}
+	return m.GetTxByHashFunc(ctx, txHash)
+}
+
+func (m *MockClient) GetPendingTransactions(ctx context.Context) (client.PendingTransactions, error) {
+	if m.GetPendingTransactionsFunc == nil {
+		return client.PendingTransactions{}, ErrNotMocked
+	}
+	return m.GetPendingTransactionsFunc(ctx)
+}
+
+func (m *MockClient) CheckHealth(ctx context.Context) (*mmnpb.HealthCheckResponse, error) {
+	if m.CheckHealthFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.CheckHealthFunc(ctx)
+}
+
+func (m *MockClient) GetCurrentNonce(ctx context.Context, addr string, tag string) (uint64, error) {
+	if m.GetCurrentNonceFunc == nil {
+		return 0, ErrNotMocked
+	}
+	return m.GetCurrentNonceFunc(ctx, addr, tag)
+}
+
+func (m *MockClient) GetBlockNumber(ctx context.Context) (uint64, error) {
+	if m.GetBlockNumberFunc == nil {
+		return 0, ErrNotMocked
+	}
+	return m.GetBlockNumberFunc(ctx)
+}
+
+func (m *MockClient) GetBlockByNumber(ctx context.Context, blockNumbers ...uint64) ([]client.Block, error) {
+	if m.GetBlockByNumberFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.GetBlockByNumberFunc(ctx, blockNumbers...)
+}
+
+func (m *MockClient) GetBlockByRange(ctx context.Context, fromSlot, toSlot uint64) (client.BlockRange, error) {
+	if m.GetBlockByRangeFunc == nil {
+		return client.BlockRange{}, ErrNotMocked
+	}
+	return m.GetBlockByRangeFunc(ctx, fromSlot, toSlot)
+}
+
+// Conn returns nil when ConnFunc is not set.
+func (m *MockClient) Conn() *grpc.ClientConn {
+	if m.ConnFunc == nil {
+		return nil
+	}
+	return m.ConnFunc()
+}
+
+// Close returns nil when CloseFunc is not set.
+func (m *MockClient) Close() error {
+	if m.CloseFunc == nil {
+		return nil
+	}
+	return m.CloseFunc()
+}
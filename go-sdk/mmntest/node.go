@@ -0,0 +1,451 @@
+// Package mmntest provides an in-process fake MMN node and a mock MainnetClient for tests.
+//
+// Node serves the TxService, AccountService, BlockService and HealthService gRPC APIs over
+// an in-memory bufconn listener, so the real client.MmnClient can be exercised without a
+// network:
+//
+//	node := mmntest.NewNode()
+//	defer node.Close()
+//	node.Fund(addr, uint256.NewInt(1_000_000))
+//	c, _ := node.Dial(client.Config{})
+//	res, _ := c.AddTx(ctx, signed)
+//	node.ProduceBlock()
+//
+// A Scenario makes the node misbehave on demand: latency, gRPC errors, rejected
+// transactions, stale nonces and dropped or reordered status streams.
+package mmntest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"github.com/mr-tron/base58"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	bufSize       = 1 << 20
+	subscriberBuf = 1024
+	nodeVersion   = "mmntest"
+)
+
+// Option configures a Node.
+type Option func(*Node)
+
+// WithAccount sets the genesis balance and nonce of addr.
+func WithAccount(addr string, balance *uint256.Int, nonce uint64) Option {
+	return func(n *Node) {
+		n.accounts[addr] = &account{balance: new(uint256.Int).Set(balance), nonce: nonce}
+	}
+}
+
+// WithDecimals sets the decimals the node reports. Defaults to client.NATIVE_DECIMAL.
+func WithDecimals(decimals uint32) Option {
+	return func(n *Node) { n.decimals = decimals }
+}
+
+// WithZkVerifier makes the node check ZK transfer proofs like a real node does.
+func WithZkVerifier(v client.ZkProofVerifier) Option {
+	return func(n *Node) { n.zk = v }
+}
+
+// WithBlockInterval makes the node produce a block every interval instead of only on ProduceBlock.
+func WithBlockInterval(interval time.Duration) Option {
+	return func(n *Node) { n.blockInterval = interval }
+}
+
+// WithReplacement lets a transaction replace the pending transaction with the same
+// sender and nonce. Without it the node rejects such a transaction as "nonce too low".
+func WithReplacement() Option {
+	return func(n *Node) { n.replace = true }
+}
+
+// WithServerOptions passes options, such as interceptors, to the gRPC server.
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(n *Node) { n.serverOpts = append(n.serverOpts, opts...) }
+}
+
+type account struct {
+	balance *uint256.Int
+	nonce   uint64
+}
+
+type txRecord struct {
+	tx        *client.Tx
+	signed    *mmnpb.SignedTxMsg
+	hash      string
+	status    mmnpb.TransactionStatus
+	slot      uint64
+	blockHash string
+	errMsg    string
+}
+
+// Node is an in-memory MMN node. All methods are safe for concurrent use.
+type Node struct {
+	mu            sync.Mutex
+	accounts      map[string]*account
+	txs           map[string]*txRecord
+	mempool       []*txRecord
+	blocks        []*mmnpb.Block
+	subscribers   map[chan *mmnpb.TransactionStatusInfo]struct{}
+	blockWatchers map[chan struct{}]struct{}
+	decimals      uint32
+	zk            client.ZkProofVerifier
+	replace       bool
+	leaderKey     ed25519.PrivateKey
+	started       time.Time
+
+	blockInterval time.Duration
+	serverOpts    []grpc.ServerOption
+	lis           *bufconn.Listener
+	srv           *grpc.Server
+	stop          chan struct{}
+	wg            sync.WaitGroup
+}
+
+// NewNode starts a fake node with a genesis block at slot 0.
+func NewNode(opts ...Option) *Node {
+	_, leaderKey, _ := ed25519.GenerateKey(nil)
+	n := &Node{
+		accounts:      make(map[string]*account),
+		txs:           make(map[string]*txRecord),
+		subscribers:   make(map[chan *mmnpb.TransactionStatusInfo]struct{}),
+		blockWatchers: make(map[chan struct{}]struct{}),
+		decimals:      client.NATIVE_DECIMAL,
+		leaderKey:     leaderKey,
+		started:       time.Now(),
+		stop:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	n.blocks = append(n.blocks, n.sealBlock(0, make([]byte, sha256.Size), nil))
+
+	n.lis = bufconn.Listen(bufSize)
+	n.srv = grpc.NewServer(n.serverOpts...)
+	mmnpb.RegisterTxServiceServer(n.srv, &txService{n: n})
+	mmnpb.RegisterAccountServiceServer(n.srv, &accountService{n: n})
+	mmnpb.RegisterBlockServiceServer(n.srv, &blockService{n: n})
+	mmnpb.RegisterHealthServiceServer(n.srv, &healthService{n: n})
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		_ = n.srv.Serve(n.lis)
+	}()
+
+	if n.blockInterval > 0 {
+		n.wg.Add(1)
+		go n.produceBlocks()
+	}
+	return n
+}
+
+// Dial returns an MmnClient connected to the node. Endpoint and UseTLS in cfg are ignored.
+func (n *Node) Dial(cfg client.Config) (*client.MmnClient, error) {
+	cfg.Endpoint = "passthrough:///mmntest"
+	cfg.UseTLS = false
+	cfg.DialOptions = append(cfg.DialOptions, grpc.WithContextDialer(n.dialer))
+	return client.NewClient(cfg)
+}
+
+func (n *Node) dialer(ctx context.Context, _ string) (net.Conn, error) {
+	return n.lis.DialContext(ctx)
+}
+
+// Close stops the server and ends all streams.
+func (n *Node) Close() {
+	close(n.stop)
+	n.srv.Stop()
+	n.wg.Wait()
+}
+
+// LeaderKey returns the public key that signs the node's blocks.
+func (n *Node) LeaderKey() ed25519.PublicKey {
+	return n.leaderKey.Public().(ed25519.PublicKey)
+}
+
+// Fund credits amount to addr, creating the account if needed.
+func (n *Node) Fund(addr string, amount *uint256.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	acc := n.account(addr)
+	acc.balance.Add(acc.balance, amount)
+}
+
+// Balance returns the committed balance of addr.
+func (n *Node) Balance(addr string) *uint256.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if acc, ok := n.accounts[addr]; ok {
+		return new(uint256.Int).Set(acc.balance)
+	}
+	return uint256.NewInt(0)
+}
+
+// Nonce returns the committed nonce of addr.
+func (n *Node) Nonce(addr string) uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if acc, ok := n.accounts[addr]; ok {
+		return acc.nonce
+	}
+	return 0
+}
+
+// PendingCount returns the number of transactions in the mempool.
+func (n *Node) PendingCount() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.mempool)
+}
+
+// ProduceBlock includes the mempool in a new block, applies the transfers and
+// emits CONFIRMED and FINALIZED status updates. It returns the new block.
+func (n *Node) ProduceBlock() *mmnpb.Block {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	prev := n.blocks[len(n.blocks)-1]
+	slot := prev.Slot + 1
+	included := n.mempool
+	n.mempool = nil
+
+	for _, rec := range included {
+		if err := n.apply(rec.tx); err != "" {
+			rec.status = mmnpb.TransactionStatus_FAILED
+			rec.errMsg = err
+		} else {
+			rec.status = mmnpb.TransactionStatus_FINALIZED
+		}
+	}
+	block := n.sealBlock(slot, prev.Hash, included)
+	n.blocks = append(n.blocks, block)
+
+	blockHash := hex.EncodeToString(block.Hash)
+	for _, rec := range included {
+		rec.slot = slot
+		rec.blockHash = blockHash
+		if rec.status == mmnpb.TransactionStatus_FAILED {
+			n.emit(rec)
+			continue
+		}
+		rec.status = mmnpb.TransactionStatus_CONFIRMED
+		n.emit(rec)
+		rec.status = mmnpb.TransactionStatus_FINALIZED
+		n.emit(rec)
+	}
+	for ch := range n.blockWatchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+
+	return proto.Clone(block).(*mmnpb.Block)
+}
+
+func (n *Node) produceBlocks() {
+	defer n.wg.Done()
+	ticker := time.NewTicker(n.blockInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case <-ticker.C:
+			n.ProduceBlock()
+		}
+	}
+}
+
+// account returns the account for addr, creating an empty one. Callers hold n.mu.
+func (n *Node) account(addr string) *account {
+	acc, ok := n.accounts[addr]
+	if !ok {
+		acc = &account{balance: uint256.NewInt(0)}
+		n.accounts[addr] = acc
+	}
+	return acc
+}
+
+// apply moves funds for tx and returns a node error message on failure. Callers hold n.mu.
+func (n *Node) apply(tx *client.Tx) string {
+	sender := n.account(tx.Sender)
+	amount := tx.Amount
+	if amount == nil {
+		amount = uint256.NewInt(0)
+	}
+	if sender.balance.Lt(amount) {
+		return "insufficient balance"
+	}
+	recipient := n.account(tx.Recipient)
+	sender.balance.Sub(sender.balance, amount)
+	recipient.balance.Add(recipient.balance, amount)
+	sender.nonce = tx.Nonce
+	return ""
+}
+
+// sealBlock builds and signs a block with BlockScheme. Each transaction gets its own
+// entry whose hash chains from the previous entry. Callers hold n.mu.
+func (n *Node) sealBlock(slot uint64, prevHash []byte, txs []*txRecord) *mmnpb.Block {
+	block := &mmnpb.Block{
+		Slot:      slot,
+		PrevHash:  prevHash,
+		LeaderId:  base58.Encode(n.LeaderKey()),
+		Timestamp: uint64(time.Now().Unix()),
+	}
+
+	entryHash := prevHash
+	for _, rec := range txs {
+		raw, _ := proto.Marshal(rec.signed)
+		entryHash, _ = nextEntryHash(entryHash, client.Entry{NumHashes: 1, TxHashes: []string{rec.hash}})
+		block.Entries = append(block.Entries, &mmnpb.Entry{
+			NumHashes:    1,
+			Hash:         entryHash,
+			Transactions: [][]byte{raw},
+			TxHashes:     []string{rec.hash},
+		})
+		block.TransactionData = append(block.TransactionData, rec.transactionData())
+	}
+
+	block.Hash = blockHash(slot, prevHash, entryHash)
+	block.Signature = ed25519.Sign(n.leaderKey, block.Hash)
+	return block
+}
+
+// emit sends the current status of rec to all subscribers, dropping it for slow ones. Callers hold n.mu.
+func (n *Node) emit(rec *txRecord) {
+	info := rec.statusInfo()
+	for ch := range n.subscribers {
+		select {
+		case ch <- proto.Clone(info).(*mmnpb.TransactionStatusInfo):
+		default:
+		}
+	}
+}
+
+func (n *Node) subscribe() chan *mmnpb.TransactionStatusInfo {
+	ch := make(chan *mmnpb.TransactionStatusInfo, subscriberBuf)
+	n.mu.Lock()
+	n.subscribers[ch] = struct{}{}
+	n.mu.Unlock()
+	return ch
+}
+
+func (n *Node) unsubscribe(ch chan *mmnpb.TransactionStatusInfo) {
+	n.mu.Lock()
+	delete(n.subscribers, ch)
+	n.mu.Unlock()
+}
+
+func (r *txRecord) statusInfo() *mmnpb.TransactionStatusInfo {
+	var confirmations uint64
+	if r.status == mmnpb.TransactionStatus_CONFIRMED || r.status == mmnpb.TransactionStatus_FINALIZED {
+		confirmations = 1
+	}
+	return &mmnpb.TransactionStatusInfo{
+		TxHash:        r.hash,
+		Status:        r.status,
+		BlockSlot:     r.slot,
+		BlockHash:     r.blockHash,
+		Confirmations: confirmations,
+		ErrorMessage:  r.errMsg,
+		Timestamp:     uint64(time.Now().Unix()),
+		ExtraInfo:     r.tx.ExtraInfo,
+		Amount:        client.Uint256ToString(r.tx.Amount),
+		TextData:      r.tx.TextData,
+		Sender:        r.tx.Sender,
+		Recipient:     r.tx.Recipient,
+	}
+}
+
+func (r *txRecord) transactionData() *mmnpb.TransactionData {
+	return &mmnpb.TransactionData{
+		TxHash:          r.hash,
+		Sender:          r.tx.Sender,
+		Recipient:       r.tx.Recipient,
+		Amount:          client.Uint256ToString(r.tx.Amount),
+		Nonce:           r.tx.Nonce,
+		Timestamp:       r.tx.Timestamp,
+		Status:          r.status,
+		TextData:        r.tx.TextData,
+		ExtraInfo:       r.tx.ExtraInfo,
+		TransactionType: int32(r.tx.Type),
+	}
+}
+
+func (r *txRecord) txInfo() *mmnpb.TxInfo {
+	return &mmnpb.TxInfo{
+		Sender:    r.tx.Sender,
+		Recipient: r.tx.Recipient,
+		Amount:    client.Uint256ToString(r.tx.Amount),
+		Timestamp: r.tx.Timestamp,
+		TextData:  r.tx.TextData,
+		Nonce:     r.tx.Nonce,
+		Slot:      r.slot,
+		Blockhash: r.blockHash,
+		Status:    r.status,
+		ErrMsg:    r.errMsg,
+		ExtraInfo: r.tx.ExtraInfo,
+		TxHash:    r.hash,
+	}
+}
+
+// TxHash is the hash the fake node assigns to tx: hex SHA-256 of its signing payload.
+func TxHash(tx *client.Tx) string {
+	sum := sha256.Sum256(client.Serialize(tx))
+	return hex.EncodeToString(sum[:])
+}
+
+// BlockScheme is how the fake node seals its blocks, for client.BlockScheme.VerifyBlock.
+// It is not known to match any production node.
+var BlockScheme = client.BlockScheme{EntryHash: nextEntryHash, BlockHash: blockHash, TxHash: TxHash, VerifyTx: client.Verify}
+
+// blockHash is the hash the leader signs: SHA-256 of the big-endian slot, the previous
+// block hash and the last entry hash.
+func blockHash(slot uint64, prevHash, lastEntryHash []byte) []byte {
+	h := sha256.New()
+	h.Write(binary.BigEndian.AppendUint64(nil, slot))
+	h.Write(prevHash)
+	h.Write(lastEntryHash)
+	return h.Sum(nil)
+}
+
+// nextEntryHash applies e's NumHashes rounds of SHA-256 to prev. With transactions, the
+// last round hashes prev together with the tx hashes instead of prev alone.
+func nextEntryHash(prev []byte, e client.Entry) ([]byte, error) {
+	rounds := e.NumHashes
+	if len(e.TxHashes) > 0 {
+		if rounds == 0 {
+			return nil, fmt.Errorf("%w: num_hashes is 0 for an entry with transactions", client.ErrBlockEntryHash)
+		}
+		rounds--
+	}
+	h := prev
+	for range rounds {
+		sum := sha256.Sum256(h)
+		h = sum[:]
+	}
+	if len(e.TxHashes) > 0 {
+		mix := sha256.New()
+		mix.Write(h)
+		for _, txHash := range e.TxHashes {
+			mix.Write([]byte(txHash))
+		}
+		h = mix.Sum(nil)
+	}
+	return h, nil
+}
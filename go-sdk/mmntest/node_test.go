@@ -0,0 +1,220 @@
+package mmntest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"github.com/mr-tron/base58"
+)
+
+type testKey struct {
+	addr string
+	pub  ed25519.PublicKey
+	seed []byte
+}
+
+func newTestKey(t *testing.T) testKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return testKey{addr: base58.Encode(pub), pub: pub, seed: priv.Seed()}
+}
+
+func (k testKey) transfer(t *testing.T, to string, amount, nonce uint64) client.SignedTx {
+	t.Helper()
+	tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, k.addr, to, uint256.NewInt(amount), nonce, uint64(time.Now().Unix()), "", nil, "", "")
+	if err != nil {
+		t.Fatalf("BuildTransferTx() error = %v", err)
+	}
+	signed, err := client.SignTx(tx, k.pub, k.seed)
+	if err != nil {
+		t.Fatalf("SignTx() error = %v", err)
+	}
+	return signed
+}
+
+// waitSubscribed waits until the server side of a status stream is registered,
+// since creating the client stream does not wait for the handler to run.
+func waitSubscribed(t *testing.T, n *Node) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		n.mu.Lock()
+		subscribed := len(n.subscribers) > 0
+		n.mu.Unlock()
+		if subscribed {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("status stream was not registered")
+}
+
+func dial(t *testing.T, n *Node) *client.MmnClient {
+	t.Helper()
+	c, err := n.Dial(client.Config{})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestNode_Transfer(t *testing.T) {
+	alice, bob := newTestKey(t), newTestKey(t)
+	n := NewNode(WithAccount(alice.addr, uint256.NewInt(1000), 0))
+	defer n.Close()
+	c := dial(t, n)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := c.SubscribeTransactionStatus(ctx)
+	if err != nil {
+		t.Fatalf("SubscribeTransactionStatus() error = %v", err)
+	}
+	waitSubscribed(t, n)
+
+	res, err := c.AddTx(ctx, alice.transfer(t, bob.addr, 300, 1))
+	if err != nil {
+		t.Fatalf("AddTx() error = %v", err)
+	}
+	if pending, _ := c.GetCurrentNonce(ctx, alice.addr, "pending"); pending != 1 {
+		t.Errorf("pending nonce = %d, want 1", pending)
+	}
+	if latest, _ := c.GetCurrentNonce(ctx, alice.addr, "latest"); latest != 0 {
+		t.Errorf("latest nonce = %d, want 0", latest)
+	}
+
+	block := n.ProduceBlock()
+	if block.Slot != 1 || len(block.TransactionData) != 1 {
+		t.Fatalf("ProduceBlock() = slot %d with %d txs, want slot 1 with 1 tx", block.Slot, len(block.TransactionData))
+	}
+
+	want := []mmnpb.TransactionStatus{
+		mmnpb.TransactionStatus_PENDING,
+		mmnpb.TransactionStatus_CONFIRMED,
+		mmnpb.TransactionStatus_FINALIZED,
+	}
+	for _, status := range want {
+		info, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if info.TxHash != res.TxHash || info.Status != status {
+			t.Fatalf("Recv() = %s %v, want %s %v", info.TxHash, info.Status, res.TxHash, status)
+		}
+	}
+
+	acc, err := c.GetAccount(ctx, bob.addr)
+	if err != nil {
+		t.Fatalf("GetAccount() error = %v", err)
+	}
+	if acc.Balance.Uint64() != 300 || n.Balance(alice.addr).Uint64() != 700 || n.Nonce(alice.addr) != 1 {
+		t.Errorf("after transfer: bob = %s, alice = %s nonce %d", acc.Balance, n.Balance(alice.addr), n.Nonce(alice.addr))
+	}
+
+	info, err := c.GetTxByHash(ctx, res.TxHash)
+	if err != nil {
+		t.Fatalf("GetTxByHash() error = %v", err)
+	}
+	if info.Status != int32(mmnpb.TransactionStatus_FINALIZED) || info.Slot != 1 {
+		t.Errorf("GetTxByHash() = status %d slot %d, want FINALIZED in slot 1", info.Status, info.Slot)
+	}
+
+	blocks, err := c.GetBlockByNumber(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetBlockByNumber() error = %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].Entries[0].TxHashes[0] != res.TxHash {
+		t.Errorf("GetBlockByNumber() = %+v, want block 1 with %s", blocks, res.TxHash)
+	}
+	if !ed25519.Verify(n.LeaderKey(), blocks[0].Hash, blocks[0].Signature) {
+		t.Error("block signature does not verify against LeaderKey")
+	}
+}
+
+func TestNode_AddTxRejects(t *testing.T) {
+	alice, bob := newTestKey(t), newTestKey(t)
+	n := NewNode(WithAccount(alice.addr, uint256.NewInt(100), 0))
+	defer n.Close()
+	c := dial(t, n)
+	ctx := context.Background()
+
+	if _, err := c.AddTx(ctx, alice.transfer(t, bob.addr, 10, 1)); err != nil {
+		t.Fatalf("AddTx() error = %v", err)
+	}
+
+	forged := alice.transfer(t, bob.addr, 10, 2)
+	forged.Tx.Amount = uint256.NewInt(90)
+
+	tests := []struct {
+		name string
+		tx   client.SignedTx
+		want error
+	}{
+		{"stale nonce", alice.transfer(t, bob.addr, 10, 1), client.ErrNonceTooLow},
+		{"nonce gap", alice.transfer(t, bob.addr, 10, 3), client.ErrNonceTooHigh},
+		{"overspend with pending", alice.transfer(t, bob.addr, 91, 2), client.ErrInsufficientBalance},
+		{"bad signature", forged, client.ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.AddTx(ctx, tt.tx)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("AddTx() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if n.PendingCount() != 1 {
+		t.Errorf("PendingCount() = %d, want 1", n.PendingCount())
+	}
+}
+
+func TestNode_AccountAndBlockErrors(t *testing.T) {
+	n := NewNode()
+	defer n.Close()
+	c := dial(t, n)
+	ctx := context.Background()
+
+	if _, err := c.GetAccount(ctx, newTestKey(t).addr); !errors.Is(err, client.ErrAccountNotFound) {
+		t.Errorf("GetAccount() error = %v, want ErrAccountNotFound", err)
+	}
+	if _, err := c.GetTxByHash(ctx, "00"); !errors.Is(err, client.ErrTxNotFound) {
+		t.Errorf("GetTxByHash() error = %v, want ErrTxNotFound", err)
+	}
+	if _, err := c.GetBlockByNumber(ctx, 5); err == nil {
+		t.Error("GetBlockByNumber() of missing slot succeeded")
+	}
+
+	n.ProduceBlock()
+	r, err := c.GetBlockByRange(ctx, 0, 3)
+	if err != nil {
+		t.Fatalf("GetBlockByRange() error = %v", err)
+	}
+	if r.TotalBlocks != 2 || len(r.Errors) != 2 || r.Decimals != client.NATIVE_DECIMAL {
+		t.Errorf("GetBlockByRange() = %d blocks, errors %v, decimals %d", r.TotalBlocks, r.Errors, r.Decimals)
+	}
+	if r.Blocks[1].PrevHash == nil || string(r.Blocks[1].PrevHash) != string(r.Blocks[0].Hash) {
+		t.Error("block 1 does not chain to block 0")
+	}
+}
+
+func TestMockClient_NotMocked(t *testing.T) {
+	m := &MockClient{
+		GetBlockNumberFunc: func(context.Context) (uint64, error) { return 7, nil },
+	}
+	if got, err := m.GetBlockNumber(context.Background()); err != nil || got != 7 {
+		t.Errorf("GetBlockNumber() = %d, %v, want 7", got, err)
+	}
+	if _, err := m.GetAccount(context.Background(), "x"); !errors.Is(err, ErrNotMocked) {
+		t.Errorf("GetAccount() error = %v, want ErrNotMocked", err)
+	}
+}
@@ -0,0 +1,301 @@
+package mmntest
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Scenario scripts faults for a Node. Declare it per test and pass it with WithScenario:
+//
+//	s := mmntest.NewScenario()
+//	s.On("AddTx").Times(2).Unavailable()
+//	s.On("AddTx").Reject("nonce too low")
+//	s.On("GetCurrentNonce").StaleNonce(1)
+//	s.On("SubscribeTransactionStatus").DropAfter(2)
+//	node := mmntest.NewNode(mmntest.WithScenario(s))
+//
+// Methods are named as in the proto services, e.g. "AddTx" or "GetBlockByRange".
+// Rules may be added while the node runs. Each call is handled by the first rule for
+// its method that is still active; calls no rule matches reach the node unchanged.
+type Scenario struct {
+	mu    sync.Mutex
+	rules []*Rule
+	calls map[string]int
+}
+
+func NewScenario() *Scenario {
+	return &Scenario{calls: make(map[string]int)}
+}
+
+// WithScenario injects the faults of s into the node's gRPC server.
+func WithScenario(s *Scenario) Option {
+	return WithServerOptions(
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+}
+
+// On adds a rule for method. Without further configuration it matches every call and does nothing.
+func (s *Scenario) On(method string) *Rule {
+	r := &Rule{s: s, method: method}
+	s.mu.Lock()
+	s.rules = append(s.rules, r)
+	s.mu.Unlock()
+	return r
+}
+
+// Calls returns how many times method was called, faulted or not.
+func (s *Scenario) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// Rule is one scripted fault. Its methods configure it in place and return it for chaining.
+type Rule struct {
+	s      *Scenario
+	method string
+	after  int
+	times  int
+	seen   int
+	fired  int
+
+	delay     time.Duration
+	latency   time.Duration
+	code      codes.Code
+	msg       string
+	reject    string
+	staleBy   uint64
+	dropAfter int
+	reorder   bool
+}
+
+// After lets the first n calls through before the rule applies.
+func (r *Rule) After(n int) *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.after = n
+	return r
+}
+
+// Times limits the rule to n calls. Zero, the default, means every call.
+func (r *Rule) Times(n int) *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.times = n
+	return r
+}
+
+// Delay adds latency before the call is handled. On streams it delays every message.
+func (r *Rule) Delay(d time.Duration) *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.delay = d
+	return r
+}
+
+// Latency delays the response of a unary call by d after the node handled it, like a
+// slow network on the way back. Unlike Delay, concurrent calls reach the node in the
+// order they were sent.
+func (r *Rule) Latency(d time.Duration) *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.latency = d
+	return r
+}
+
+// Fail makes the call return a gRPC status error.
+func (r *Rule) Fail(code codes.Code, msg string) *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.code, r.msg = code, msg
+	return r
+}
+
+// Unavailable is Fail(codes.Unavailable, "node unavailable").
+func (r *Rule) Unavailable() *Rule {
+	return r.Fail(codes.Unavailable, "node unavailable")
+}
+
+// Reject makes AddTx answer Ok:false with errMsg without admitting the transaction.
+func (r *Rule) Reject(errMsg string) *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.reject = errMsg
+	return r
+}
+
+// StaleNonce makes GetCurrentNonce and GetAccount report a nonce by lower than the node's.
+func (r *Rule) StaleNonce(by uint64) *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.staleBy = by
+	return r
+}
+
+// DropAfter ends a stream with codes.Unavailable after it has sent n messages.
+func (r *Rule) DropAfter(n int) *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.dropAfter = n
+	return r
+}
+
+// Reorder swaps each pair of consecutive messages on a stream, so that for example
+// FINALIZED arrives before CONFIRMED. A message without a successor is held until
+// the next one is sent.
+func (r *Rule) Reorder() *Rule {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.reorder = true
+	return r
+}
+
+// fault is a snapshot of the rule applied to one call.
+type fault struct {
+	delay     time.Duration
+	latency   time.Duration
+	code      codes.Code
+	msg       string
+	reject    string
+	staleBy   uint64
+	dropAfter int
+	reorder   bool
+}
+
+// match counts the call and returns the fault of the first active rule for method.
+func (s *Scenario) match(method string) (fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[method]++
+
+	for _, r := range s.rules {
+		if r.method != method {
+			continue
+		}
+		r.seen++
+		if r.seen <= r.after || (r.times > 0 && r.fired >= r.times) {
+			continue
+		}
+		r.fired++
+		return fault{
+			delay:     r.delay,
+			latency:   r.latency,
+			code:      r.code,
+			msg:       r.msg,
+			reject:    r.reject,
+			staleBy:   r.staleBy,
+			dropAfter: r.dropAfter,
+			reorder:   r.reorder,
+		}, true
+	}
+	return fault{}, false
+}
+
+func (s *Scenario) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	f, ok := s.match(path.Base(info.FullMethod))
+	if !ok {
+		return handler(ctx, req)
+	}
+	if err := sleep(ctx, f.delay); err != nil {
+		return nil, err
+	}
+	if f.code != codes.OK {
+		return nil, status.Error(f.code, f.msg)
+	}
+	if f.reject != "" {
+		if _, ok := req.(*mmnpb.SignedTxMsg); ok {
+			return &mmnpb.AddTxResponse{Ok: false, Error: f.reject}, nil
+		}
+	}
+
+	resp, err := handler(ctx, req)
+	if serr := sleep(ctx, f.latency); serr != nil {
+		return nil, serr
+	}
+	if err != nil || f.staleBy == 0 {
+		return resp, err
+	}
+	switch res := resp.(type) {
+	case *mmnpb.GetCurrentNonceResponse:
+		res.Nonce = staleNonce(res.Nonce, f.staleBy)
+	case *mmnpb.GetAccountResponse:
+		res.Nonce = staleNonce(res.Nonce, f.staleBy)
+	}
+	return resp, nil
+}
+
+func (s *Scenario) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	f, ok := s.match(path.Base(info.FullMethod))
+	if !ok {
+		return handler(srv, ss)
+	}
+	if f.code != codes.OK {
+		if err := sleep(ss.Context(), f.delay); err != nil {
+			return err
+		}
+		return status.Error(f.code, f.msg)
+	}
+	return handler(srv, &faultyStream{ServerStream: ss, f: f})
+}
+
+// faultyStream applies a fault to the messages a server stream sends.
+type faultyStream struct {
+	grpc.ServerStream
+	f    fault
+	sent int
+	held any
+}
+
+func (fs *faultyStream) SendMsg(m any) error {
+	if err := sleep(fs.Context(), fs.f.delay); err != nil {
+		return err
+	}
+	if fs.f.reorder {
+		if fs.held == nil {
+			fs.held = m
+			return nil
+		}
+		if err := fs.send(m); err != nil {
+			return err
+		}
+		m, fs.held = fs.held, nil
+	}
+	return fs.send(m)
+}
+
+func (fs *faultyStream) send(m any) error {
+	if fs.f.dropAfter > 0 && fs.sent >= fs.f.dropAfter {
+		return status.Error(codes.Unavailable, "stream dropped")
+	}
+	fs.sent++
+	return fs.ServerStream.SendMsg(m)
+}
+
+func staleNonce(nonce, by uint64) uint64 {
+	if nonce < by {
+		return 0
+	}
+	return nonce - by
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,136 @@
+package mmntest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestScenario_AddTx(t *testing.T) {
+	alice, bob := newTestKey(t), newTestKey(t)
+	s := NewScenario()
+	s.On("AddTx").Times(2).Unavailable()
+	s.On("AddTx").Times(1).Reject("nonce too low")
+	n := NewNode(WithAccount(alice.addr, uint256.NewInt(100), 0), WithScenario(s))
+	defer n.Close()
+	c := dial(t, n)
+	ctx := context.Background()
+	tx := alice.transfer(t, bob.addr, 10, 1)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.AddTx(ctx, tx); !client.IsRetryable(err) {
+			t.Fatalf("AddTx() #%d error = %v, want retryable", i, err)
+		}
+	}
+	if _, err := c.AddTx(ctx, tx); !errors.Is(err, client.ErrNonceTooLow) {
+		t.Fatalf("AddTx() error = %v, want ErrNonceTooLow", err)
+	}
+	if n.PendingCount() != 0 {
+		t.Fatalf("PendingCount() = %d after faults, want 0", n.PendingCount())
+	}
+	if _, err := c.AddTx(ctx, tx); err != nil {
+		t.Fatalf("AddTx() after faults error = %v", err)
+	}
+	if got := s.Calls("AddTx"); got != 4 {
+		t.Errorf("Calls(AddTx) = %d, want 4", got)
+	}
+
+	// With Latency the node admits the transaction before the caller gives up on it.
+	s.On("AddTx").Latency(time.Second)
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.AddTx(tctx, alice.transfer(t, bob.addr, 10, 2)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AddTx() with latency error = %v, want DeadlineExceeded", err)
+	}
+	if n.PendingCount() != 2 {
+		t.Errorf("PendingCount() after a late answer = %d, want 2", n.PendingCount())
+	}
+}
+
+func TestScenario_StaleNonceAndDelay(t *testing.T) {
+	alice := newTestKey(t)
+	s := NewScenario()
+	s.On("GetCurrentNonce").After(1).StaleNonce(2)
+	s.On("GetBlockNumber").Delay(time.Second)
+	n := NewNode(WithAccount(alice.addr, uint256.NewInt(0), 5), WithScenario(s))
+	defer n.Close()
+	c := dial(t, n)
+
+	if got, _ := c.GetCurrentNonce(context.Background(), alice.addr, "latest"); got != 5 {
+		t.Errorf("first GetCurrentNonce() = %d, want 5", got)
+	}
+	if got, _ := c.GetCurrentNonce(context.Background(), alice.addr, "latest"); got != 3 {
+		t.Errorf("stale GetCurrentNonce() = %d, want 3", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetBlockNumber(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("delayed GetBlockNumber() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestScenario_StatusStream(t *testing.T) {
+	alice, bob := newTestKey(t), newTestKey(t)
+	s := NewScenario()
+	s.On("SubscribeTransactionStatus").Times(1).Reorder().DropAfter(3)
+	n := NewNode(WithAccount(alice.addr, uint256.NewInt(100), 0), WithScenario(s))
+	defer n.Close()
+	c := dial(t, n)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := c.SubscribeTransactionStatus(ctx)
+	if err != nil {
+		t.Fatalf("SubscribeTransactionStatus() error = %v", err)
+	}
+	waitSubscribed(t, n)
+	if _, err := c.AddTx(ctx, alice.transfer(t, bob.addr, 10, 1)); err != nil {
+		t.Fatalf("AddTx() error = %v", err)
+	}
+	if _, err := c.AddTx(ctx, alice.transfer(t, bob.addr, 10, 2)); err != nil {
+		t.Fatalf("AddTx() error = %v", err)
+	}
+	n.ProduceBlock()
+
+	// Events in node order are PENDING(1) PENDING(2) CONFIRMED(1) FINALIZED(1) ...;
+	// swapped pairwise and cut after three messages.
+	want := []struct {
+		nonce  uint64
+		status mmnpb.TransactionStatus
+	}{
+		{2, mmnpb.TransactionStatus_PENDING},
+		{1, mmnpb.TransactionStatus_PENDING},
+		{1, mmnpb.TransactionStatus_FINALIZED},
+	}
+	nonceOf := map[string]uint64{}
+	n.mu.Lock()
+	for _, rec := range n.txs {
+		nonceOf[rec.hash] = rec.tx.Nonce
+	}
+	n.mu.Unlock()
+	for i, w := range want {
+		info, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv() #%d error = %v", i, err)
+		}
+		if nonceOf[info.TxHash] != w.nonce || info.Status != w.status {
+			t.Errorf("Recv() #%d = nonce %d %v, want nonce %d %v", i, nonceOf[info.TxHash], info.Status, w.nonce, w.status)
+		}
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Recv() after drop error = %v, want Unavailable", err)
+	}
+
+	// The rule fired once, so a new subscription is healthy.
+	if _, err := c.SubscribeTransactionStatus(ctx); err != nil {
+		t.Fatalf("resubscribe error = %v", err)
+	}
+}
@@ -0,0 +1,359 @@
+package mmntest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Error strings returned by the fake node. They match the wording of a real node so
+// that client.ParseNodeError classifies them.
+const (
+	errInvalidTx           = "invalid transaction"
+	errInvalidAddress      = "invalid address"
+	errInvalidSignature    = "invalid signature"
+	errInvalidZkProof      = "invalid zk proof"
+	errNonceTooLow         = "nonce too low"
+	errNonceTooHigh        = "nonce too high"
+	errInsufficientBalance = "insufficient balance"
+	errDuplicateTx         = "duplicate transaction"
+	errTxNotFound          = "tx not found"
+	errAccountNotFound     = "account not found"
+	errBlockNotFound       = "block not found"
+)
+
+type txService struct {
+	mmnpb.UnimplementedTxServiceServer
+	n *Node
+}
+
+func (s *txService) AddTx(_ context.Context, in *mmnpb.SignedTxMsg) (*mmnpb.AddTxResponse, error) {
+	rec, errMsg := s.n.addTx(in)
+	if errMsg != "" {
+		return &mmnpb.AddTxResponse{Ok: false, Error: errMsg}, nil
+	}
+	return &mmnpb.AddTxResponse{Ok: true, TxHash: rec.hash}, nil
+}
+
+func (s *txService) GetTxByHash(_ context.Context, in *mmnpb.GetTxByHashRequest) (*mmnpb.GetTxByHashResponse, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	rec, ok := n.txs[in.TxHash]
+	if !ok {
+		return &mmnpb.GetTxByHashResponse{Error: errTxNotFound}, nil
+	}
+	return &mmnpb.GetTxByHashResponse{Tx: rec.txInfo(), Decimals: n.decimals}, nil
+}
+
+func (s *txService) SubscribeTransactionStatus(_ *mmnpb.SubscribeTransactionStatusRequest, stream mmnpb.TxService_SubscribeTransactionStatusServer) error {
+	ch := s.n.subscribe()
+	defer s.n.unsubscribe(ch)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.n.stop:
+			return nil
+		case info := <-ch:
+			if err := stream.Send(info); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *txService) GetPendingTransactions(_ context.Context, _ *mmnpb.GetPendingTransactionsRequest) (*mmnpb.GetPendingTransactionsResponse, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	res := &mmnpb.GetPendingTransactionsResponse{TotalCount: uint64(len(n.mempool))}
+	for _, rec := range n.mempool {
+		res.PendingTxs = append(res.PendingTxs, rec.transactionData())
+	}
+	return res, nil
+}
+
+// addTx validates in the way a node does before admitting it to the mempool.
+// It returns the node error message when the transaction is rejected.
+func (n *Node) addTx(in *mmnpb.SignedTxMsg) (*txRecord, string) {
+	signed, err := client.FromProtoSigTx(in)
+	if err != nil {
+		return nil, errInvalidTx
+	}
+	tx := signed.Tx
+	if client.ValidateAddress(tx.Sender) != nil || client.ValidateAddress(tx.Recipient) != nil {
+		return nil, errInvalidAddress
+	}
+	if !client.Verify(tx, signed.Sig) {
+		return nil, errInvalidSignature
+	}
+	if n.zk != nil {
+		if err := client.VerifySignedZkProof(n.zk, signed); err != nil {
+			return nil, fmt.Sprintf("%s: %v", errInvalidZkProof, err)
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	hash := TxHash(tx)
+	replaced, at := n.takeReplaced(tx)
+	errMsg := n.admit(tx, replaced != nil)
+	if _, ok := n.txs[hash]; ok && errMsg == "" {
+		errMsg = errDuplicateTx
+	}
+	if errMsg != "" {
+		if replaced != nil {
+			n.mempool = slices.Insert(n.mempool, at, replaced)
+		}
+		return nil, errMsg
+	}
+	if replaced != nil {
+		replaced.status = mmnpb.TransactionStatus_FAILED
+		replaced.errMsg = "replaced by " + hash
+		n.emit(replaced)
+	}
+
+	rec := &txRecord{
+		tx:     tx,
+		signed: proto.Clone(in).(*mmnpb.SignedTxMsg),
+		hash:   hash,
+		status: mmnpb.TransactionStatus_PENDING,
+	}
+	n.txs[hash] = rec
+	if replaced != nil {
+		n.mempool = slices.Insert(n.mempool, at, rec)
+	} else {
+		n.mempool = append(n.mempool, rec)
+	}
+	n.emit(rec)
+	return rec, ""
+}
+
+// admit checks the nonce and balance of tx against the committed state and the mempool.
+// A replacement takes a nonce already in use, so its nonce is not checked. Callers hold n.mu.
+func (n *Node) admit(tx *client.Tx, replacing bool) string {
+	acc := n.account(tx.Sender)
+	pendingNonce, pendingOut := n.pendingOf(tx.Sender)
+	switch {
+	case replacing:
+	case tx.Nonce <= pendingNonce:
+		return errNonceTooLow
+	case tx.Nonce > pendingNonce+1:
+		return errNonceTooHigh
+	}
+	need := new(uint256.Int).Add(pendingOut, tx.Amount)
+	if acc.balance.Lt(need) {
+		return errInsufficientBalance
+	}
+	return ""
+}
+
+// takeReplaced removes the pending transaction tx replaces, if replacement is enabled,
+// and returns it with its mempool index. Callers hold n.mu.
+func (n *Node) takeReplaced(tx *client.Tx) (*txRecord, int) {
+	if !n.replace {
+		return nil, 0
+	}
+	for i, rec := range n.mempool {
+		if rec.tx.Sender == tx.Sender && rec.tx.Nonce == tx.Nonce {
+			n.mempool = slices.Delete(n.mempool, i, i+1)
+			return rec, i
+		}
+	}
+	return nil, 0
+}
+
+// pendingOf returns the highest nonce of addr including the mempool, and the amount
+// its pending transactions spend. Callers hold n.mu.
+func (n *Node) pendingOf(addr string) (uint64, *uint256.Int) {
+	nonce := uint64(0)
+	if acc, ok := n.accounts[addr]; ok {
+		nonce = acc.nonce
+	}
+	out := uint256.NewInt(0)
+	for _, rec := range n.mempool {
+		if rec.tx.Sender != addr {
+			continue
+		}
+		if rec.tx.Nonce > nonce {
+			nonce = rec.tx.Nonce
+		}
+		out.Add(out, rec.tx.Amount)
+	}
+	return nonce, out
+}
+
+type accountService struct {
+	mmnpb.UnimplementedAccountServiceServer
+	n *Node
+}
+
+func (s *accountService) GetAccount(_ context.Context, in *mmnpb.GetAccountRequest) (*mmnpb.GetAccountResponse, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	acc, ok := n.accounts[in.Address]
+	if !ok {
+		return nil, status.Error(codes.NotFound, errAccountNotFound)
+	}
+	return &mmnpb.GetAccountResponse{
+		Address:  in.Address,
+		Balance:  acc.balance.Dec(),
+		Nonce:    acc.nonce,
+		Decimals: n.decimals,
+	}, nil
+}
+
+func (s *accountService) GetCurrentNonce(_ context.Context, in *mmnpb.GetCurrentNonceRequest) (*mmnpb.GetCurrentNonceResponse, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	res := &mmnpb.GetCurrentNonceResponse{Address: in.Address, Tag: in.Tag}
+	switch in.Tag {
+	case "latest":
+		if acc, ok := n.accounts[in.Address]; ok {
+			res.Nonce = acc.nonce
+		}
+	case "pending":
+		res.Nonce, _ = n.pendingOf(in.Address)
+	default:
+		res.Error = fmt.Sprintf("invalid tag %q: must be latest or pending", in.Tag)
+	}
+	return res, nil
+}
+
+type blockService struct {
+	mmnpb.UnimplementedBlockServiceServer
+	n *Node
+}
+
+func (s *blockService) GetBlockNumber(_ context.Context, _ *mmnpb.EmptyParams) (*mmnpb.GetBlockNumberResponse, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return &mmnpb.GetBlockNumberResponse{BlockNumber: n.blocks[len(n.blocks)-1].Slot}, nil
+}
+
+func (s *blockService) GetBlockByNumber(_ context.Context, in *mmnpb.GetBlockByNumberRequest) (*mmnpb.GetBlockByNumberResponse, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	res := &mmnpb.GetBlockByNumberResponse{Decimals: n.decimals}
+	for _, num := range in.BlockNumbers {
+		if num >= uint64(len(n.blocks)) {
+			return &mmnpb.GetBlockByNumberResponse{Error: fmt.Sprintf("%s: %d", errBlockNotFound, num)}, nil
+		}
+		res.Blocks = append(res.Blocks, proto.Clone(n.blocks[num]).(*mmnpb.Block))
+	}
+	return res, nil
+}
+
+func (s *blockService) GetBlockByRange(_ context.Context, in *mmnpb.GetBlockByRangeRequest) (*mmnpb.GetBlockByRangeResponse, error) {
+	n := s.n
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	res := &mmnpb.GetBlockByRangeResponse{Decimals: n.decimals}
+	if in.FromSlot > in.ToSlot {
+		res.Errors = append(res.Errors, fmt.Sprintf("invalid range: from %d > to %d", in.FromSlot, in.ToSlot))
+		return res, nil
+	}
+	for slot := in.FromSlot; slot <= in.ToSlot; slot++ {
+		if slot >= uint64(len(n.blocks)) {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s: %d", errBlockNotFound, slot))
+			continue
+		}
+		b := n.blocks[slot]
+		res.Blocks = append(res.Blocks, &mmnpb.BlockInfo{
+			Slot:            b.Slot,
+			PrevHash:        b.PrevHash,
+			LeaderId:        b.LeaderId,
+			Timestamp:       b.Timestamp,
+			Hash:            b.Hash,
+			Signature:       b.Signature,
+			TransactionData: cloneTransactionData(b.TransactionData),
+		})
+	}
+	res.TotalBlocks = uint32(len(res.Blocks))
+	return res, nil
+}
+
+func cloneTransactionData(list []*mmnpb.TransactionData) []*mmnpb.TransactionData {
+	out := make([]*mmnpb.TransactionData, 0, len(list))
+	for _, d := range list {
+		out = append(out, proto.Clone(d).(*mmnpb.TransactionData))
+	}
+	return out
+}
+
+type healthService struct {
+	mmnpb.UnimplementedHealthServiceServer
+	n *Node
+}
+
+func (s *healthService) Check(_ context.Context, _ *mmnpb.Empty) (*mmnpb.HealthCheckResponse, error) {
+	return s.n.health(), nil
+}
+
+func (s *healthService) Watch(_ *mmnpb.Empty, stream mmnpb.HealthService_WatchServer) error {
+	n := s.n
+	ch := make(chan struct{}, 1)
+	n.mu.Lock()
+	n.blockWatchers[ch] = struct{}{}
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.blockWatchers, ch)
+		n.mu.Unlock()
+	}()
+
+	if err := stream.Send(n.health()); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-n.stop:
+			return nil
+		case <-ch:
+			if err := stream.Send(n.health()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (n *Node) health() *mmnpb.HealthCheckResponse {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	slot := n.blocks[len(n.blocks)-1].Slot
+	return &mmnpb.HealthCheckResponse{
+		Status:      mmnpb.HealthCheckResponse_SERVING,
+		NodeId:      n.blocks[0].LeaderId,
+		Timestamp:   uint64(time.Now().Unix()),
+		CurrentSlot: slot,
+		BlockHeight: slot,
+		MempoolSize: uint64(len(n.mempool)),
+		IsLeader:    true,
+		Version:     nodeVersion,
+		Uptime:      uint64(time.Since(n.started).Seconds()),
+	}
+}
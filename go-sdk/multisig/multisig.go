@@ -0,0 +1,215 @@
+// Package multisig implements M-of-N ed25519 accounts.
+//
+// A Policy of N public keys and a threshold M derives a multisig address. A transfer
+// from it travels between signers as a PartialTx, a JSON document holding the transaction,
+// the policy and the signatures collected so far. Signers add their signature with Sign,
+// partial transactions signed in parallel are merged with Combine, and Finalize packs M
+// signatures into an Envelope carried in SignedTx.Sig, like UserSig for ZK transfers.
+//
+// Verify checks an envelope locally. Nodes do not verify multisig envelopes yet and
+// reject them as an invalid signature; the envelope is versioned so a finalized
+// transaction can be submitted unchanged once they do.
+package multisig
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mr-tron/base58"
+)
+
+// Version is the version of the address derivation and the envelope format.
+const Version = 1
+
+const (
+	addressTag = "mmn-multisig-v1"
+	maxKeys    = 255
+)
+
+var (
+	ErrInvalidPolicy      = errors.New("multisig: invalid policy")
+	ErrNotSigner          = errors.New("multisig: key is not part of the policy")
+	ErrSenderMismatch     = errors.New("multisig: transaction sender is not the policy address")
+	ErrTxMismatch         = errors.New("multisig: partial transactions sign different payloads")
+	ErrBadSignature       = errors.New("multisig: signature does not verify")
+	ErrMissingTx          = errors.New("multisig: partial transaction has no tx")
+	ErrNotEnoughSigs      = errors.New("multisig: not enough signatures")
+	ErrInvalidEnvelope    = errors.New("multisig: invalid envelope")
+	ErrUnsupportedVersion = errors.New("multisig: unsupported version")
+)
+
+// Policy is a threshold and a set of keys. Keys are kept sorted, so a policy and its
+// address do not depend on the order the keys were given in.
+type Policy struct {
+	Threshold int
+	Keys      []ed25519.PublicKey
+}
+
+// NewPolicy validates and normalizes an M-of-N policy.
+func NewPolicy(threshold int, keys ...ed25519.PublicKey) (Policy, error) {
+	if len(keys) == 0 || len(keys) > maxKeys {
+		return Policy{}, fmt.Errorf("%w: %d keys, want 1 to %d", ErrInvalidPolicy, len(keys), maxKeys)
+	}
+	if threshold < 1 || threshold > len(keys) {
+		return Policy{}, fmt.Errorf("%w: threshold %d of %d keys", ErrInvalidPolicy, threshold, len(keys))
+	}
+
+	sorted := make([]ed25519.PublicKey, len(keys))
+	for i, k := range keys {
+		if len(k) != ed25519.PublicKeySize {
+			return Policy{}, fmt.Errorf("%w: key %d has %d bytes", ErrInvalidPolicy, i, len(k))
+		}
+		sorted[i] = append(ed25519.PublicKey(nil), k...)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Equal(sorted[i-1]) {
+			return Policy{}, fmt.Errorf("%w: duplicate key %s", ErrInvalidPolicy, base58.Encode(sorted[i]))
+		}
+	}
+	return Policy{Threshold: threshold, Keys: sorted}, nil
+}
+
+// Address derives the account address of p:
+//
+//	base58(SHA-256("mmn-multisig-v1" || M || N || key_1 || ... || key_N))
+//
+// with M and N as single bytes and the keys in sorted order. The domain tag keeps it
+// apart from key and user-ID addresses.
+func (p Policy) Address() string {
+	h := sha256.New()
+	h.Write([]byte(addressTag))
+	h.Write([]byte{byte(p.Threshold), byte(len(p.Keys))})
+	for _, k := range p.Keys {
+		h.Write(k)
+	}
+	return base58.Encode(h.Sum(nil))
+}
+
+// Index returns the position of pub in p, or -1.
+func (p Policy) Index(pub ed25519.PublicKey) int {
+	for i, k := range p.Keys {
+		if k.Equal(pub) {
+			return i
+		}
+	}
+	return -1
+}
+
+type policyJSON struct {
+	Threshold int      `json:"threshold"`
+	Keys      []string `json:"keys"`
+}
+
+func (p Policy) MarshalJSON() ([]byte, error) {
+	keys := make([]string, len(p.Keys))
+	for i, k := range p.Keys {
+		keys[i] = base58.Encode(k)
+	}
+	return json.Marshal(policyJSON{Threshold: p.Threshold, Keys: keys})
+}
+
+// UnmarshalJSON decodes and validates a policy with NewPolicy.
+func (p *Policy) UnmarshalJSON(data []byte) error {
+	var raw policyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	keys := make([]ed25519.PublicKey, len(raw.Keys))
+	for i, k := range raw.Keys {
+		decoded, err := base58.Decode(k)
+		if err != nil {
+			return fmt.Errorf("%w: key %d: %v", ErrInvalidPolicy, i, err)
+		}
+		keys[i] = decoded
+	}
+	policy, err := NewPolicy(raw.Threshold, keys...)
+	if err != nil {
+		return err
+	}
+	*p = policy
+	return nil
+}
+
+// Envelope is the multisig signature of a finalized transaction, carried in SignedTx.Sig
+// as base58 of its JSON encoding.
+type Envelope struct {
+	Version    int          `json:"version"`
+	Policy     Policy       `json:"policy"`
+	Signatures []IndexedSig `json:"signatures"`
+}
+
+// IndexedSig is a signature by the key at Index in the policy.
+type IndexedSig struct {
+	Index int    `json:"index"`
+	Sig   []byte `json:"sig"`
+}
+
+// Encode returns the SignedTx.Sig form of e.
+func (e Envelope) Encode() (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return base58.Encode(data), nil
+}
+
+// DecodeEnvelope parses the SignedTx.Sig form of an envelope.
+func DecodeEnvelope(sig string) (Envelope, error) {
+	data, err := base58.Decode(sig)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	var e Envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Envelope{}, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	if e.Version != Version {
+		return Envelope{}, fmt.Errorf("%w: envelope version %d", ErrUnsupportedVersion, e.Version)
+	}
+	return e, nil
+}
+
+// VerifyEnvelope checks that e authorizes tx: tx.Sender is the policy address and at
+// least Threshold distinct keys signed client.Serialize(tx).
+func VerifyEnvelope(tx *client.Tx, e Envelope) error {
+	if tx.Sender != e.Policy.Address() {
+		return ErrSenderMismatch
+	}
+	msg := client.Serialize(tx)
+	seen := make(map[int]bool, len(e.Signatures))
+	for _, s := range e.Signatures {
+		if s.Index < 0 || s.Index >= len(e.Policy.Keys) || seen[s.Index] {
+			return fmt.Errorf("%w: signature index %d", ErrInvalidEnvelope, s.Index)
+		}
+		seen[s.Index] = true
+		if !ed25519.Verify(e.Policy.Keys[s.Index], msg, s.Sig) {
+			return fmt.Errorf("%w: key %d", ErrBadSignature, s.Index)
+		}
+	}
+	if len(seen) < e.Policy.Threshold {
+		return fmt.Errorf("%w: %d of %d", ErrNotEnoughSigs, len(seen), e.Policy.Threshold)
+	}
+	return nil
+}
+
+// Verify is the multisig counterpart of client.Verify: it reports whether sig is an
+// envelope that authorizes tx.
+func Verify(tx *client.Tx, sig string) bool {
+	e, err := DecodeEnvelope(sig)
+	if err != nil {
+		return false
+	}
+	return VerifyEnvelope(tx, e) == nil
+}
+
+// VerifyAny accepts a key, ZK or multisig signature of tx.
+func VerifyAny(tx *client.Tx, sig string) bool {
+	return client.Verify(tx, sig) || Verify(tx, sig)
+}
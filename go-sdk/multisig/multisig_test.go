@@ -0,0 +1,169 @@
+package multisig
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+)
+
+func newKeys(t *testing.T, n int) ([]ed25519.PublicKey, []ed25519.PrivateKey) {
+	t.Helper()
+	pubs := make([]ed25519.PublicKey, n)
+	privs := make([]ed25519.PrivateKey, n)
+	for i := range pubs {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs[i], privs[i] = pub, priv
+	}
+	return pubs, privs
+}
+
+func TestPolicy(t *testing.T) {
+	pubs, _ := newKeys(t, 3)
+	a, err := NewPolicy(2, pubs[0], pubs[1], pubs[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewPolicy(2, pubs[2], pubs[0], pubs[1])
+	if a.Address() != b.Address() {
+		t.Error("Address() depends on key order")
+	}
+	c, _ := NewPolicy(3, pubs...)
+	if a.Address() == c.Address() {
+		t.Error("Address() ignores the threshold")
+	}
+
+	for name, tc := range map[string]struct {
+		m    int
+		keys []ed25519.PublicKey
+	}{
+		"zero threshold":  {0, pubs},
+		"threshold above": {4, pubs},
+		"duplicate":       {1, []ed25519.PublicKey{pubs[0], pubs[0]}},
+		"short key":       {1, []ed25519.PublicKey{pubs[0][:16]}},
+	} {
+		if _, err := NewPolicy(tc.m, tc.keys...); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("%s: NewPolicy() error = %v", name, err)
+		}
+	}
+}
+
+func TestPartialTx_SignCombineFinalize(t *testing.T) {
+	pubs, privs := newKeys(t, 3)
+	policy, _ := NewPolicy(2, pubs...)
+	tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, policy.Address(), client.GenerateAddress("alice"),
+		uint256.NewInt(5), 1, 0, "payroll", nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPartialTx(policy, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Sign(privs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Finalize(); !errors.Is(err, ErrNotEnoughSigs) {
+		t.Errorf("Finalize() with 1 of 2 error = %v", err)
+	}
+	_, stranger := newKeys(t, 1)
+	if err := p.Sign(stranger[0]); !errors.Is(err, ErrNotSigner) {
+		t.Errorf("Sign() by stranger error = %v", err)
+	}
+
+	// The second signer works on a copy passed around as JSON.
+	data, err := p.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := DecodePartialTx(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Sign(privs[2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Combine(q); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Ready() || len(p.Missing()) != 1 {
+		t.Fatalf("Ready() = %v, Missing() = %d keys", p.Ready(), len(p.Missing()))
+	}
+
+	signed, err := p.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(signed.Tx, signed.Sig) || !VerifyAny(signed.Tx, signed.Sig) {
+		t.Error("Verify() rejects the finalized envelope")
+	}
+	if client.Verify(signed.Tx, signed.Sig) {
+		t.Error("client.Verify() accepts a multisig envelope")
+	}
+
+	tampered := *signed.Tx
+	tampered.Amount = uint256.NewInt(500)
+	if Verify(&tampered, signed.Sig) {
+		t.Error("Verify() accepts a changed amount")
+	}
+	if err := p.Combine(&PartialTx{Policy: policy, Tx: &tampered}); !errors.Is(err, ErrTxMismatch) {
+		t.Errorf("Combine() of another payload error = %v", err)
+	}
+
+	// Incoming signatures are verified before any is merged.
+	fresh, _ := NewPartialTx(policy, tx)
+	forged := &PartialTx{Policy: policy, Tx: tx, Signatures: []IndexedSig{
+		{Index: 0, Sig: ed25519.Sign(privs[0], client.Serialize(tx))},
+		{Index: 1, Sig: ed25519.Sign(privs[0], client.Serialize(tx))},
+	}}
+	if err := fresh.Combine(forged); !errors.Is(err, ErrBadSignature) || len(fresh.Signatures) != 0 {
+		t.Errorf("Combine() of a forged signature = %v, %d merged", err, len(fresh.Signatures))
+	}
+	if err := fresh.Combine(&PartialTx{Policy: policy}); !errors.Is(err, ErrMissingTx) {
+		t.Errorf("Combine() without tx error = %v", err)
+	}
+	if _, err := (&PartialTx{Policy: policy}).Finalize(); !errors.Is(err, ErrMissingTx) {
+		t.Errorf("Finalize() without tx error = %v", err)
+	}
+}
+
+func TestVerifyEnvelope_Rejects(t *testing.T) {
+	pubs, privs := newKeys(t, 2)
+	policy, _ := NewPolicy(2, pubs...)
+	tx, _ := client.BuildTransferTx(client.TxTypeTransferByKey, policy.Address(), client.GenerateAddress("bob"),
+		uint256.NewInt(1), 1, 0, "", nil, "", "")
+	msg := client.Serialize(tx)
+	// Policy keys are sorted, so look up where the first key ended up.
+	i0 := policy.Index(pubs[0])
+	sig0 := IndexedSig{Index: i0, Sig: ed25519.Sign(privs[0], msg)}
+
+	e := Envelope{Version: Version, Policy: policy, Signatures: []IndexedSig{sig0, sig0}}
+	if err := VerifyEnvelope(tx, e); !errors.Is(err, ErrInvalidEnvelope) {
+		t.Errorf("repeated signer error = %v", err)
+	}
+	e.Signatures = []IndexedSig{sig0}
+	if err := VerifyEnvelope(tx, e); !errors.Is(err, ErrNotEnoughSigs) {
+		t.Errorf("below threshold error = %v", err)
+	}
+	e.Signatures = []IndexedSig{sig0, {Index: 1 - i0, Sig: ed25519.Sign(privs[0], msg)}}
+	if err := VerifyEnvelope(tx, e); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("wrong key error = %v", err)
+	}
+
+	other := *tx
+	other.Sender = client.GenerateAddress("carol")
+	if err := VerifyEnvelope(&other, e); !errors.Is(err, ErrSenderMismatch) {
+		t.Errorf("foreign sender error = %v", err)
+	}
+	e.Version = Version + 1
+	sig, _ := e.Encode()
+	if _, err := DecodeEnvelope(sig); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("DecodeEnvelope() of future version error = %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package multisig
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+)
+
+// PartialTx is a multisig transaction being signed. Its JSON encoding is the format
+// passed between signers.
+type PartialTx struct {
+	Version    int          `json:"version"`
+	Policy     Policy       `json:"policy"`
+	Tx         *client.Tx   `json:"tx"`
+	Signatures []IndexedSig `json:"signatures"`
+}
+
+// NewPartialTx starts collecting signatures for tx, whose sender must be the policy address.
+func NewPartialTx(policy Policy, tx *client.Tx) (*PartialTx, error) {
+	if tx.Sender != policy.Address() {
+		return nil, ErrSenderMismatch
+	}
+	return &PartialTx{Version: Version, Policy: policy, Tx: tx}, nil
+}
+
+// DecodePartialTx parses and checks a partial transaction produced by Encode. Every
+// signature already present must verify.
+func DecodePartialTx(data []byte) (*PartialTx, error) {
+	var p PartialTx
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("multisig: decode partial transaction: %w", err)
+	}
+	if p.Version != Version {
+		return nil, fmt.Errorf("%w: partial transaction version %d", ErrUnsupportedVersion, p.Version)
+	}
+	if p.Tx == nil {
+		return nil, fmt.Errorf("multisig: decode partial transaction: %w", ErrMissingTx)
+	}
+	if p.Tx.Sender != p.Policy.Address() {
+		return nil, ErrSenderMismatch
+	}
+	if err := p.verifySigs(p.Signatures); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// verifySigs checks that each of sigs is a signature of p.Tx by the policy key it names.
+func (p *PartialTx) verifySigs(sigs []IndexedSig) error {
+	msg := client.Serialize(p.Tx)
+	for _, s := range sigs {
+		if s.Index < 0 || s.Index >= len(p.Policy.Keys) || !ed25519.Verify(p.Policy.Keys[s.Index], msg, s.Sig) {
+			return fmt.Errorf("%w: key %d", ErrBadSignature, s.Index)
+		}
+	}
+	return nil
+}
+
+func (p *PartialTx) Encode() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// Sign adds the signature of priv, which must belong to a key of the policy.
+// Signing twice with the same key replaces the earlier signature.
+func (p *PartialTx) Sign(priv ed25519.PrivateKey) error {
+	if p.Tx == nil {
+		return ErrMissingTx
+	}
+	idx := p.Policy.Index(priv.Public().(ed25519.PublicKey))
+	if idx < 0 {
+		return ErrNotSigner
+	}
+	p.add(IndexedSig{Index: idx, Sig: ed25519.Sign(priv, client.Serialize(p.Tx))})
+	return nil
+}
+
+// Combine merges the signatures of others, which must sign the same transaction under
+// the same policy. Every incoming signature must verify; on any error nothing is merged.
+func (p *PartialTx) Combine(others ...*PartialTx) error {
+	if p.Tx == nil {
+		return ErrMissingTx
+	}
+	addr, payload := p.Policy.Address(), string(client.Serialize(p.Tx))
+	for _, o := range others {
+		if o == nil || o.Tx == nil {
+			return ErrMissingTx
+		}
+		if o.Policy.Address() != addr || string(client.Serialize(o.Tx)) != payload {
+			return ErrTxMismatch
+		}
+		if err := p.verifySigs(o.Signatures); err != nil {
+			return err
+		}
+	}
+	for _, o := range others {
+		for _, s := range o.Signatures {
+			p.add(s)
+		}
+	}
+	return nil
+}
+
+func (p *PartialTx) add(sig IndexedSig) {
+	for i, s := range p.Signatures {
+		if s.Index == sig.Index {
+			p.Signatures[i] = sig
+			return
+		}
+	}
+	p.Signatures = append(p.Signatures, sig)
+	sort.Slice(p.Signatures, func(i, j int) bool { return p.Signatures[i].Index < p.Signatures[j].Index })
+}
+
+// Missing returns the keys that have not signed yet.
+func (p *PartialTx) Missing() []ed25519.PublicKey {
+	signed := make(map[int]bool, len(p.Signatures))
+	for _, s := range p.Signatures {
+		signed[s.Index] = true
+	}
+	var missing []ed25519.PublicKey
+	for i, k := range p.Policy.Keys {
+		if !signed[i] {
+			missing = append(missing, k)
+		}
+	}
+	return missing
+}
+
+// Ready reports whether the threshold is met.
+func (p *PartialTx) Ready() bool {
+	return p.Tx != nil && len(p.Signatures) >= p.Policy.Threshold
+}
+
+// Finalize packs Threshold signatures into an envelope and returns the transaction to submit.
+func (p *PartialTx) Finalize() (client.SignedTx, error) {
+	if p.Tx == nil {
+		return client.SignedTx{}, ErrMissingTx
+	}
+	if !p.Ready() {
+		return client.SignedTx{}, fmt.Errorf("%w: %d of %d", ErrNotEnoughSigs, len(p.Signatures), p.Policy.Threshold)
+	}
+	e := Envelope{
+		Version:    Version,
+		Policy:     p.Policy,
+		Signatures: append([]IndexedSig(nil), p.Signatures[:p.Policy.Threshold]...),
+	}
+	if err := VerifyEnvelope(p.Tx, e); err != nil {
+		return client.SignedTx{}, err
+	}
+	sig, err := e.Encode()
+	if err != nil {
+		return client.SignedTx{}, err
+	}
+	return client.SignedTx{Tx: p.Tx, Sig: sig}, nil
+}
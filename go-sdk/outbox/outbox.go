@@ -0,0 +1,511 @@
+// Package outbox tracks signed transactions from submission to a final outcome across
+// process restarts.
+//
+// Send persists a transaction before broadcasting it. Run follows the status stream and
+// periodically reconciles unsettled entries against the chain, rebroadcasting those the
+// node has not accepted or has forgotten. Every entry ends finalized or failed, and the
+// matching callback runs once for it; only an entry whose outcome cannot be looked up
+// without Options.TxHash stays pending.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	mmnpb "github.com/mezonai/mmn-sdk/go-sdk/proto"
+)
+
+const (
+	defaultReconcileInterval = 10 * time.Second
+	defaultRebroadcastAfter  = time.Minute
+	defaultDroppedAfter      = 3
+	resubscribeDelay         = time.Second
+)
+
+var (
+	ErrOutcomeUnknown = errors.New("outbox: node rejected a rebroadcast as already known, but the transaction cannot be looked up")
+	ErrNonceReused    = errors.New("outbox: transaction is unknown to the node and its nonce was used by another transaction")
+)
+
+// Client is the part of client.MainnetClient the outbox uses.
+type Client interface {
+	AddTx(ctx context.Context, tx client.SignedTx) (client.AddTxResponse, error)
+	GetTxByHash(ctx context.Context, txHash string) (client.TxInfo, error)
+	SubscribeTransactionStatus(ctx context.Context) (mmnpb.TxService_SubscribeTransactionStatusClient, error)
+}
+
+type State string
+
+const (
+	// StatePending entries are stored but not yet accepted by the node.
+	StatePending State = "pending"
+	// StateSent entries were accepted and wait for finalization.
+	StateSent      State = "sent"
+	StateFinalized State = "finalized"
+	StateFailed    State = "failed"
+)
+
+// Terminal reports whether s is a final outcome.
+func (s State) Terminal() bool {
+	return s == StateFinalized || s == StateFailed
+}
+
+// Entry is a transaction tracked by the outbox. ID is client.TxID of the transaction.
+type Entry struct {
+	ID       string          `json:"id"`
+	Tx       client.SignedTx `json:"tx"`
+	TxHash   string          `json:"tx_hash,omitempty"`
+	State    State           `json:"state"`
+	Attempts int             `json:"attempts"`
+	// Misses counts the rebroadcasts of a sent entry rejected as a used nonce while the
+	// node did not know the transaction.
+	Misses    int       `json:"misses,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Notified  bool      `json:"notified"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Options struct {
+	// OnFinalized and OnFailed are called once per entry that reaches the state.
+	OnFinalized func(Entry)
+	OnFailed    func(Entry)
+	// OnError receives errors of the background work of Run. Optional.
+	OnError func(error)
+	// ReconcileInterval is how often Run checks unsettled entries. Defaults to 10s.
+	ReconcileInterval time.Duration
+	// RebroadcastAfter is how long a sent entry may be unknown to the node before it is
+	// sent again. Defaults to 1m.
+	RebroadcastAfter time.Duration
+	// DroppedAfter is how many rebroadcasts of a sent entry the node may reject as a used
+	// nonce, while it does not know the transaction, before the entry fails with
+	// ErrNonceReused. Defaults to 3.
+	DroppedAfter int
+	// TxHash computes the hash the node assigns to a transaction. When set, an entry
+	// whose AddTx response was lost in a crash can be looked up. Without it, such an
+	// entry stays pending and every Reconcile reports ErrOutcomeUnknown for it.
+	TxHash func(tx *client.Tx) string
+}
+
+// Outbox is safe for concurrent use.
+type Outbox struct {
+	c     Client
+	store Store
+	opts  Options
+
+	mu        sync.Mutex
+	entries   map[string]*Entry
+	byHash    map[string]string
+	notifying map[string]bool
+	sending   map[string]bool
+}
+
+// New loads the entries of store. Entries left unsettled by a previous process are
+// picked up by the next Reconcile or Run.
+func New(c Client, store Store, opts Options) (*Outbox, error) {
+	if opts.ReconcileInterval <= 0 {
+		opts.ReconcileInterval = defaultReconcileInterval
+	}
+	if opts.RebroadcastAfter <= 0 {
+		opts.RebroadcastAfter = defaultRebroadcastAfter
+	}
+	if opts.DroppedAfter <= 0 {
+		opts.DroppedAfter = defaultDroppedAfter
+	}
+
+	stored, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	o := &Outbox{
+		c:         c,
+		store:     store,
+		opts:      opts,
+		entries:   make(map[string]*Entry, len(stored)),
+		byHash:    make(map[string]string),
+		notifying: make(map[string]bool),
+		sending:   make(map[string]bool),
+	}
+	for i := range stored {
+		e := stored[i]
+		o.entries[e.ID] = &e
+		if e.TxHash != "" {
+			o.byHash[e.TxHash] = e.ID
+		}
+	}
+	return o, nil
+}
+
+// Send stores tx and broadcasts it. Sending a transaction that is already in the outbox
+// returns its entry without broadcasting again. A retryable error leaves the entry
+// pending for Run to rebroadcast; other rejections settle it as failed.
+func (o *Outbox) Send(ctx context.Context, tx client.SignedTx) (Entry, error) {
+	id := client.TxID(tx.Tx)
+	now := time.Now()
+
+	o.mu.Lock()
+	if e, ok := o.entries[id]; ok {
+		o.mu.Unlock()
+		return *e, nil
+	}
+	e := &Entry{ID: id, Tx: tx, State: StatePending, CreatedAt: now, UpdatedAt: now}
+	if err := o.store.Put(*e); err != nil {
+		o.mu.Unlock()
+		return Entry{}, err
+	}
+	o.entries[id] = e
+	// Claimed before unlocking, so a concurrent Reconcile does not send it too.
+	o.sending[id] = true
+	o.mu.Unlock()
+
+	err := o.send(ctx, id)
+	entry, _ := o.Get(id)
+	return entry, err
+}
+
+// Get returns the entry with id.
+func (o *Outbox) Get(id string) (Entry, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	e, ok := o.entries[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return *e, true
+}
+
+// Entries returns all entries, oldest first.
+func (o *Outbox) Entries() []Entry {
+	o.mu.Lock()
+	entries := make([]Entry, 0, len(o.entries))
+	for _, e := range o.entries {
+		entries = append(entries, *e)
+	}
+	o.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	return entries
+}
+
+// Prune removes settled entries whose callback has run and that were last updated before t.
+func (o *Outbox) Prune(t time.Time) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := 0
+	for id, e := range o.entries {
+		if !e.State.Terminal() || !e.Notified || !e.UpdatedAt.Before(t) {
+			continue
+		}
+		if err := o.store.Delete(id); err != nil {
+			return n, err
+		}
+		delete(o.entries, id)
+		delete(o.byHash, e.TxHash)
+		n++
+	}
+	return n, nil
+}
+
+// Run follows the status stream and reconciles every Options.ReconcileInterval until
+// ctx ends. It reconciles once on start, which recovers the work of a crashed process.
+func (o *Outbox) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		o.follow(ctx)
+	}()
+	defer wg.Wait()
+
+	ticker := time.NewTicker(o.opts.ReconcileInterval)
+	defer ticker.Stop()
+	for {
+		if err := o.Reconcile(ctx); err != nil && ctx.Err() == nil {
+			o.reportError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile checks every unsettled entry once: pending entries are broadcast, sent
+// entries are looked up and settled or rebroadcast, and settled entries whose callback
+// did not run are notified.
+func (o *Outbox) Reconcile(ctx context.Context) error {
+	var errs []error
+	for _, e := range o.Entries() {
+		var err error
+		switch {
+		case e.State.Terminal():
+			o.notify(e.ID)
+		case e.State == StatePending:
+			err = o.broadcast(ctx, e.ID)
+			if client.IsRetryable(err) {
+				err = nil
+			}
+		case e.State == StateSent:
+			err = o.check(ctx, e)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("outbox: entry %s: %w", e.ID, err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// check looks up a sent entry on chain.
+func (o *Outbox) check(ctx context.Context, e Entry) error {
+	info, err := o.c.GetTxByHash(ctx, e.TxHash)
+	switch {
+	case errors.Is(err, client.ErrTxNotFound):
+		if time.Since(e.UpdatedAt) < o.opts.RebroadcastAfter {
+			return nil
+		}
+		if err := o.broadcast(ctx, e.ID); err != nil && !client.IsRetryable(err) {
+			return err
+		}
+		return nil
+	case err != nil:
+		if client.IsRetryable(err) {
+			return nil
+		}
+		return err
+	}
+
+	switch client.TxMeta_Status(info.Status) {
+	case client.TxMeta_Status_FINALIZED:
+		return o.settle(e.ID, StateFinalized, "")
+	case client.TxMeta_Status_FAILED:
+		return o.settle(e.ID, StateFailed, info.ErrMsg)
+	}
+	if e.Misses > 0 {
+		return o.update(e.ID, func(e *Entry) { e.Error, e.Misses = "", 0 })
+	}
+	return nil
+}
+
+// broadcast sends the entry with id and records the outcome, unless it is being sent
+// already.
+func (o *Outbox) broadcast(ctx context.Context, id string) error {
+	o.mu.Lock()
+	if o.sending[id] {
+		o.mu.Unlock()
+		return nil
+	}
+	o.sending[id] = true
+	o.mu.Unlock()
+	return o.send(ctx, id)
+}
+
+// send broadcasts the entry with id, which the caller has marked in o.sending.
+func (o *Outbox) send(ctx context.Context, id string) error {
+	defer func() {
+		o.mu.Lock()
+		delete(o.sending, id)
+		o.mu.Unlock()
+	}()
+
+	o.mu.Lock()
+	e, ok := o.entries[id]
+	if !ok || e.State.Terminal() {
+		o.mu.Unlock()
+		return nil
+	}
+	// The attempt is stored first so that a rebroadcast after a crash knows the node
+	// may already have the transaction.
+	e.Attempts++
+	e.UpdatedAt = time.Now()
+	if err := o.store.Put(*e); err != nil {
+		o.mu.Unlock()
+		return err
+	}
+	tx, attempts, state, misses := e.Tx, e.Attempts, e.State, e.Misses
+	o.mu.Unlock()
+
+	res, err := o.c.AddTx(ctx, tx)
+	switch {
+	case err == nil:
+		return o.update(id, func(e *Entry) {
+			e.State, e.TxHash, e.Error, e.Misses = StateSent, res.TxHash, "", 0
+		})
+	case client.IsRetryable(err) || ctx.Err() != nil:
+		_ = o.update(id, func(e *Entry) { e.Error = err.Error() })
+		return err
+	case state == StateSent && errors.Is(err, client.ErrNonceTooLow):
+		// Only sent entries the node did not know are rebroadcast, so another transaction
+		// may have taken the nonce. Give finalization a few rounds to show up first.
+		if misses+1 >= o.opts.DroppedAfter {
+			if serr := o.settle(id, StateFailed, fmt.Sprintf("%v: %v", ErrNonceReused, err)); serr != nil {
+				return serr
+			}
+			return fmt.Errorf("%w: %v", ErrNonceReused, err)
+		}
+		return o.update(id, func(e *Entry) { e.Error, e.Misses = err.Error(), misses+1 })
+	case state == StateSent && alreadyKnown(err):
+		return o.update(id, func(e *Entry) { e.Error, e.Misses = "", 0 })
+	case attempts > 1 && alreadyKnown(err):
+		if o.opts.TxHash == nil {
+			// The node may well have it; failing it could make the caller pay twice.
+			err = fmt.Errorf("%w: %v", ErrOutcomeUnknown, err)
+			_ = o.update(id, func(e *Entry) { e.Error = err.Error() })
+			return err
+		}
+		hash := o.opts.TxHash(tx.Tx)
+		return o.update(id, func(e *Entry) {
+			e.State, e.TxHash, e.Error = StateSent, hash, ""
+		})
+	default:
+		if serr := o.settle(id, StateFailed, err.Error()); serr != nil {
+			return serr
+		}
+		return err
+	}
+}
+
+// alreadyKnown reports whether err means the node already has the transaction or its nonce.
+func alreadyKnown(err error) bool {
+	return errors.Is(err, client.ErrDuplicateTx) || errors.Is(err, client.ErrNonceTooLow)
+}
+
+// update applies fn to a non-terminal entry and stores it.
+func (o *Outbox) update(id string, fn func(*Entry)) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	e, ok := o.entries[id]
+	if !ok || e.State.Terminal() {
+		return nil
+	}
+	next := *e
+	fn(&next)
+	next.UpdatedAt = time.Now()
+	if err := o.store.Put(next); err != nil {
+		return err
+	}
+	*e = next
+	if e.TxHash != "" {
+		o.byHash[e.TxHash] = id
+	}
+	return nil
+}
+
+// settle moves an entry to a terminal state and notifies. Entries that are already
+// settled are left alone, so concurrent settles of one entry notify once.
+func (o *Outbox) settle(id string, state State, errMsg string) error {
+	o.mu.Lock()
+	e, ok := o.entries[id]
+	if !ok || e.State.Terminal() {
+		o.mu.Unlock()
+		return nil
+	}
+	next := *e
+	next.State, next.Error, next.UpdatedAt = state, errMsg, time.Now()
+	if err := o.store.Put(next); err != nil {
+		o.mu.Unlock()
+		return err
+	}
+	*e = next
+	o.mu.Unlock()
+
+	o.notify(id)
+	return nil
+}
+
+// notify runs the callback of a settled entry and records that it ran. The callback runs
+// at most once per process; it runs again after a restart only if the process died
+// before the record was stored.
+func (o *Outbox) notify(id string) {
+	o.mu.Lock()
+	e, ok := o.entries[id]
+	if !ok || !e.State.Terminal() || e.Notified || o.notifying[id] {
+		o.mu.Unlock()
+		return
+	}
+	o.notifying[id] = true
+	entry := *e
+	o.mu.Unlock()
+
+	cb := o.opts.OnFinalized
+	if entry.State == StateFailed {
+		cb = o.opts.OnFailed
+	}
+	if cb != nil {
+		cb(entry)
+	}
+
+	o.mu.Lock()
+	e.Notified = true
+	err := o.store.Put(*e)
+	o.mu.Unlock()
+	if err != nil {
+		o.reportError(err)
+	}
+}
+
+// follow settles entries from the status stream, resubscribing when it ends.
+func (o *Outbox) follow(ctx context.Context) {
+	for ctx.Err() == nil {
+		stream, err := o.c.SubscribeTransactionStatus(ctx)
+		if err == nil {
+			err = o.consume(client.NewTxStatusStream(stream))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		o.reportError(fmt.Errorf("outbox: status stream: %w", err))
+
+		t := time.NewTimer(resubscribeDelay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (o *Outbox) consume(stream *client.TxStatusStream) error {
+	for {
+		upd, err := stream.Recv()
+		if errors.Is(err, client.ErrMalformedAmount) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		o.mu.Lock()
+		id, ok := o.byHash[upd.TxHash]
+		o.mu.Unlock()
+		if !ok {
+			continue
+		}
+		switch upd.Status {
+		case client.TxMeta_Status_FINALIZED:
+			err = o.settle(id, StateFinalized, "")
+		case client.TxMeta_Status_FAILED:
+			err = o.settle(id, StateFailed, upd.ErrorMessage)
+		}
+		if err != nil {
+			o.reportError(err)
+		}
+	}
+}
+
+// reportError passes err to Options.OnError. It must not be called with o.mu held.
+func (o *Outbox) reportError(err error) {
+	if o.opts.OnError != nil {
+		o.opts.OnError(err)
+	}
+}
@@ -0,0 +1,363 @@
+package outbox
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/holiman/uint256"
+	"github.com/mezonai/mmn-sdk/go-sdk/client"
+	"github.com/mezonai/mmn-sdk/go-sdk/mmntest"
+	"github.com/mr-tron/base58"
+)
+
+type callbacks struct {
+	mu        sync.Mutex
+	finalized map[string]int
+	failed    map[string]int
+}
+
+func newCallbacks() *callbacks {
+	return &callbacks{finalized: map[string]int{}, failed: map[string]int{}}
+}
+
+func (cb *callbacks) options() Options {
+	return Options{
+		OnFinalized: func(e Entry) { cb.mu.Lock(); cb.finalized[e.ID]++; cb.mu.Unlock() },
+		OnFailed:    func(e Entry) { cb.mu.Lock(); cb.failed[e.ID]++; cb.mu.Unlock() },
+	}
+}
+
+func (cb *callbacks) counts(id string) (int, int) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.finalized[id], cb.failed[id]
+}
+
+type fixture struct {
+	node      *mmntest.Node
+	client    *client.MmnClient
+	scenario  *mmntest.Scenario
+	sender    string
+	recipient string
+	pub       ed25519.PublicKey
+	priv      ed25519.PrivateKey
+}
+
+func newFixture(t *testing.T, balance uint64) *fixture {
+	t.Helper()
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	to, _, _ := ed25519.GenerateKey(nil)
+	f := &fixture{scenario: mmntest.NewScenario(), sender: base58.Encode(pub), recipient: base58.Encode(to), pub: pub, priv: priv}
+	f.node = mmntest.NewNode(mmntest.WithAccount(f.sender, uint256.NewInt(balance), 0), mmntest.WithScenario(f.scenario))
+	t.Cleanup(f.node.Close)
+	c, err := f.node.Dial(client.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	f.client = c
+	return f
+}
+
+func (f *fixture) transfer(t *testing.T, amount, nonce uint64) client.SignedTx {
+	t.Helper()
+	tx, err := client.BuildTransferTx(client.TxTypeTransferByKey, f.sender, f.recipient, uint256.NewInt(amount), nonce, 0, "", nil, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := client.SignTx(tx, f.pub, f.priv.Seed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutbox_FinalizeFromStream(t *testing.T) {
+	f := newFixture(t, 100)
+	cb := newCallbacks()
+	opts := cb.options()
+	opts.ReconcileInterval = time.Hour
+	ob, err := New(f.client, NewMemoryStore(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- ob.Run(ctx) }()
+	waitFor(t, "status subscription", func() bool { return f.scenario.Calls("SubscribeTransactionStatus") > 0 })
+
+	ok, err := ob.Send(ctx, f.transfer(t, 10, 1))
+	if err != nil || ok.State != StateSent {
+		t.Fatalf("Send() = %+v, %v", ok, err)
+	}
+	bad, err := ob.Send(ctx, f.transfer(t, 1000, 2))
+	if !errors.Is(err, client.ErrInsufficientBalance) || bad.State != StateFailed {
+		t.Fatalf("Send() overspend = %+v, %v", bad, err)
+	}
+	if again, err := ob.Send(ctx, f.transfer(t, 10, 1)); err != nil || again.ID != ok.ID {
+		t.Fatalf("Send() duplicate = %+v, %v", again, err)
+	}
+
+	f.node.ProduceBlock()
+	waitFor(t, "finalization", func() bool { n, _ := cb.counts(ok.ID); return n == 1 })
+	if err := ob.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+
+	if fin, failed := cb.counts(ok.ID); fin != 1 || failed != 0 {
+		t.Errorf("callbacks for sent entry = %d finalized, %d failed", fin, failed)
+	}
+	if fin, failed := cb.counts(bad.ID); fin != 0 || failed != 1 {
+		t.Errorf("callbacks for rejected entry = %d finalized, %d failed", fin, failed)
+	}
+	if got := f.scenario.Calls("AddTx"); got != 2 {
+		t.Errorf("AddTx calls = %d, want 2", got)
+	}
+}
+
+func TestOutbox_RecoverAfterRestart(t *testing.T) {
+	f := newFixture(t, 100)
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.scenario.On("AddTx").Times(1).Unavailable()
+
+	first, err := New(f.client, store, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := first.Send(context.Background(), f.transfer(t, 10, 1))
+	if !client.IsRetryable(err) || e.State != StatePending {
+		t.Fatalf("Send() while unavailable = %+v, %v", e, err)
+	}
+
+	// A new process picks up the stored entry.
+	cb := newCallbacks()
+	second, err := New(f.client, store, cb.options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := second.Get(e.ID); got.State != StateSent || got.Attempts != 2 {
+		t.Fatalf("after reconcile = %+v", got)
+	}
+	f.node.ProduceBlock()
+	for i := 0; i < 2; i++ {
+		if err := second.Reconcile(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fin, _ := cb.counts(e.ID); fin != 1 {
+		t.Errorf("OnFinalized calls = %d, want 1", fin)
+	}
+
+	// The settled and notified state is durable.
+	third, err := New(f.client, store, cb.options())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := third.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if fin, _ := cb.counts(e.ID); fin != 1 {
+		t.Errorf("OnFinalized calls after restart = %d, want 1", fin)
+	}
+	if n, err := third.Prune(time.Now().Add(time.Second)); n != 1 || err != nil {
+		t.Errorf("Prune() = %d, %v", n, err)
+	}
+}
+
+func TestOutbox_LostResponse(t *testing.T) {
+	f := newFixture(t, 100)
+	tx := f.transfer(t, 10, 1)
+	// The process died after the node accepted the transaction but before the response was stored.
+	if _, err := f.client.AddTx(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	lost := Entry{ID: client.TxID(tx.Tx), Tx: tx, State: StatePending, Attempts: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	store := NewMemoryStore()
+	store.Put(lost)
+	ob, err := New(f.client, store, Options{TxHash: mmntest.TxHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ob.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	f.node.ProduceBlock()
+	if err := ob.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ob.Get(lost.ID); got.State != StateFinalized {
+		t.Errorf("entry = %+v, want finalized", got)
+	}
+
+	// Without TxHash the outcome is unknown: the entry is not failed, which could make
+	// the caller pay twice.
+	store.Put(lost)
+	cb := newCallbacks()
+	ob, _ = New(f.client, store, cb.options())
+	if err := ob.Reconcile(context.Background()); !errors.Is(err, ErrOutcomeUnknown) {
+		t.Errorf("Reconcile() without TxHash error = %v, want ErrOutcomeUnknown", err)
+	}
+	if got, _ := ob.Get(lost.ID); got.State != StatePending {
+		t.Errorf("entry without TxHash = %+v, want pending", got)
+	}
+	if _, failed := cb.counts(lost.ID); failed != 0 {
+		t.Errorf("OnFailed calls without TxHash = %d, want 0", failed)
+	}
+}
+
+func TestOutbox_SendDuringReconcile(t *testing.T) {
+	f := newFixture(t, 100)
+	f.scenario.On("AddTx").Times(1).Latency(100 * time.Millisecond)
+	cb := newCallbacks()
+	ob, err := New(f.client, NewMemoryStore(), cb.options())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan Entry)
+	go func() {
+		e, _ := ob.Send(context.Background(), f.transfer(t, 10, 1))
+		sent <- e
+	}()
+	waitFor(t, "AddTx call", func() bool { return f.scenario.Calls("AddTx") == 1 })
+	// The entry is pending while Send waits for the node; Reconcile must not send it again.
+	if err := ob.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	e := <-sent
+	if e.State != StateSent || e.Attempts != 1 {
+		t.Errorf("Send() = %+v", e)
+	}
+	if got := f.scenario.Calls("AddTx"); got != 1 {
+		t.Errorf("AddTx calls = %d, want 1", got)
+	}
+	if _, failed := cb.counts(e.ID); failed != 0 {
+		t.Errorf("OnFailed calls = %d, want 0", failed)
+	}
+}
+
+func TestOutbox_NonceReused(t *testing.T) {
+	f := newFixture(t, 100)
+	dropped := f.transfer(t, 10, 1)
+	// The node dropped the sent transaction and another one took its nonce.
+	if _, err := f.client.AddTx(context.Background(), f.transfer(t, 20, 1)); err != nil {
+		t.Fatal(err)
+	}
+	f.node.ProduceBlock()
+	id := client.TxID(dropped.Tx)
+	store := NewMemoryStore()
+	store.Put(Entry{ID: id, Tx: dropped, TxHash: id, State: StateSent, Attempts: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()})
+
+	cb := newCallbacks()
+	opts := cb.options()
+	opts.RebroadcastAfter = time.Nanosecond
+	ob, err := New(f.client, store, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < defaultDroppedAfter; i++ {
+		if err := ob.Reconcile(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := ob.Get(id); got.State != StateSent || got.Misses != i {
+			t.Fatalf("after %d checks = %+v", i, got)
+		}
+	}
+	if err := ob.Reconcile(context.Background()); !errors.Is(err, ErrNonceReused) {
+		t.Errorf("Reconcile() error = %v, want ErrNonceReused", err)
+	}
+	if got, _ := ob.Get(id); got.State != StateFailed {
+		t.Errorf("entry = %+v, want failed", got)
+	}
+	if _, failed := cb.counts(id); failed != 1 {
+		t.Errorf("OnFailed calls = %d, want 1", failed)
+	}
+}
+
+// notifyFailStore fails to record that a callback ran.
+type notifyFailStore struct{ *MemoryStore }
+
+func (s notifyFailStore) Put(e Entry) error {
+	if e.Notified {
+		return errors.New("disk full")
+	}
+	return s.MemoryStore.Put(e)
+}
+
+func TestOutbox_OnErrorCallsBack(t *testing.T) {
+	f := newFixture(t, 100)
+	var ob *Outbox
+	reported := make(chan int, 1)
+	opts := Options{OnError: func(error) { reported <- len(ob.Entries()) }}
+	ob, err := New(f.client, notifyFailStore{NewMemoryStore()}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go ob.Send(context.Background(), f.transfer(t, 1000, 1))
+	select {
+	case n := <-reported:
+		if n != 1 {
+			t.Errorf("Entries() in OnError = %d entries, want 1", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnError did not return; it was called with the outbox locked")
+	}
+}
+
+func TestBoltStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.db")
+	store, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := store.Put(Entry{ID: id, State: StatePending}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Put(Entry{ID: "a", State: StateSent}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	if store, err = NewBoltStore(path); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	entries, err := store.List()
+	if err != nil || len(entries) != 1 || entries[0].ID != "a" || entries[0].State != StateSent {
+		t.Errorf("List() after reopen = %+v, %v", entries, err)
+	}
+}
@@ -0,0 +1,213 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Store persists outbox entries. Put must be durable when it returns: the outbox relies
+// on it to not lose a transaction, or report it twice, across a crash.
+type Store interface {
+	Put(e Entry) error
+	Delete(id string) error
+	List() ([]Entry, error)
+}
+
+// FileStore keeps one JSON file per entry in a directory. Writes go to a temporary file
+// that is synced and renamed over the entry, so a crash leaves the old or the new version.
+type FileStore struct {
+	dir string
+}
+
+const entryExt = ".json"
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("outbox: create store: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Put(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("outbox: encode entry %s: %w", e.ID, err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, e.ID+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("outbox: write entry %s: %w", e.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("outbox: write entry %s: %w", e.ID, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("outbox: sync entry %s: %w", e.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("outbox: write entry %s: %w", e.ID, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(e.ID)); err != nil {
+		return fmt.Errorf("outbox: write entry %s: %w", e.ID, err)
+	}
+	return s.syncDir()
+}
+
+func (s *FileStore) Delete(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("outbox: delete entry %s: %w", id, err)
+	}
+	return s.syncDir()
+}
+
+func (s *FileStore) List() ([]Entry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: list store: %w", err)
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), entryExt) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("outbox: read %s: %w", f.Name(), err)
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("outbox: decode %s: %w", f.Name(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+entryExt)
+}
+
+// syncDir makes a rename or removal in the directory durable.
+func (s *FileStore) syncDir() error {
+	d, err := os.Open(s.dir)
+	if err != nil {
+		return fmt.Errorf("outbox: sync store: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("outbox: sync store: %w", err)
+	}
+	return nil
+}
+
+// BoltStore keeps the entries in a bbolt database, one JSON value per entry. Every
+// write is a synced bbolt transaction.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+var bucketEntries = []byte("outbox")
+
+// NewBoltStore opens or creates the database at path. Close it when done.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("outbox: open store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketEntries)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("outbox: open store %s: %w", path, err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) Put(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("outbox: encode entry %s: %w", e.ID, err)
+	}
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketEntries).Put([]byte(e.ID), data)
+	}); err != nil {
+		return fmt.Errorf("outbox: write entry %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+func (s *BoltStore) Delete(id string) error {
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketEntries).Delete([]byte(id))
+	}); err != nil {
+		return fmt.Errorf("outbox: delete entry %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *BoltStore) List() ([]Entry, error) {
+	var entries []Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketEntries).ForEach(func(k, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return fmt.Errorf("outbox: decode entry %s: %w", k, err)
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// MemoryStore is a Store that does not survive a restart, for tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Put(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[e.ID] = e
+	return nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+	return nil
+}
+
+func (s *MemoryStore) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,193 @@
+// Package zkcircuit builds Groth16 ZK transfer proofs for tests and local development.
+//
+// TransferCircuit has the public input layout checked by zkverify: a commitment, the user ID,
+// the public key and the sender, with the last two reduced mod BN254. It is not the
+// production circuit, which also proves the user's login; proofs made here only verify
+// against a verifying key exported from the same Keys.
+package zkcircuit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	nativemimc "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/hash/mimc"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/zkverify"
+)
+
+// TransferCircuit proves knowledge of Secret such that
+// Commitment = MiMC(Secret, UserID, PubKey, Sender).
+type TransferCircuit struct {
+	Commitment frontend.Variable `gnark:",public"`
+	UserID     frontend.Variable `gnark:",public"`
+	PubKey     frontend.Variable `gnark:",public"`
+	Sender     frontend.Variable `gnark:",public"`
+	Secret     frontend.Variable
+}
+
+func (c *TransferCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.Secret, c.UserID, c.PubKey, c.Sender)
+	api.AssertIsEqual(h.Sum(), c.Commitment)
+	return nil
+}
+
+// Assignment is the input of a ZK transfer proof.
+type Assignment struct {
+	// UserID is encoded like production proofs: its ASCII bytes as a big-endian integer.
+	UserID string
+	Sender string
+	PubKey string
+	Secret *big.Int
+}
+
+// Proof is a proof and public witness in the base64 form of Tx.ZkProof and Tx.ZkPub.
+type Proof struct {
+	ProofB64 string
+	PubB64   string
+}
+
+// Keys holds the compiled circuit and its Groth16 keys.
+type Keys struct {
+	ccs constraint.ConstraintSystem
+	pk  groth16.ProvingKey
+	vk  groth16.VerifyingKey
+}
+
+func compile() (constraint.ConstraintSystem, error) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &TransferCircuit{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile transfer circuit: %w", err)
+	}
+	return ccs, nil
+}
+
+// Setup compiles the circuit and runs a fresh, insecure single-party trusted setup.
+func Setup() (*Keys, error) {
+	ccs, err := compile()
+	if err != nil {
+		return nil, err
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run groth16 setup: %w", err)
+	}
+	return &Keys{ccs: ccs, pk: pk, vk: vk}, nil
+}
+
+// Load compiles the circuit and reads keys written by WriteProvingKey and WriteVerifyingKey,
+// so fixtures can be regenerated against a verifying key that is already deployed.
+func Load(pkReader, vkReader io.Reader) (*Keys, error) {
+	ccs, err := compile()
+	if err != nil {
+		return nil, err
+	}
+	pk := groth16.NewProvingKey(ecc.BN254)
+	if _, err := pk.ReadFrom(pkReader); err != nil {
+		return nil, fmt.Errorf("failed to read proving key: %w", err)
+	}
+	vkB64, err := io.ReadAll(vkReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zk verifying key: %w", err)
+	}
+	vk, err := zkverify.ParseVerifyingKey(vkB64, zkverify.EncodingBase64)
+	if err != nil {
+		return nil, err
+	}
+	return &Keys{ccs: ccs, pk: pk, vk: vk}, nil
+}
+
+func (k *Keys) VerifyingKey() groth16.VerifyingKey {
+	return k.vk
+}
+
+// WriteVerifyingKey writes the verifying key as base64, the format read by zkverify.NewZkVerify.
+func (k *Keys) WriteVerifyingKey(w io.Writer) error {
+	var buf bytes.Buffer
+	if _, err := k.vk.WriteTo(&buf); err != nil {
+		return fmt.Errorf("failed to serialize verifying key: %w", err)
+	}
+	_, err := io.WriteString(w, base64.StdEncoding.EncodeToString(buf.Bytes()))
+	return err
+}
+
+// WriteProvingKey writes the binary proving key.
+func (k *Keys) WriteProvingKey(w io.Writer) error {
+	_, err := k.pk.WriteTo(w)
+	return err
+}
+
+// Prove builds a proof for a. The commitment is derived from the other inputs.
+func (k *Keys) Prove(a Assignment) (Proof, error) {
+	if a.Secret == nil {
+		return Proof{}, fmt.Errorf("zkcircuit: secret is required")
+	}
+	userID := UserIDToField(a.UserID)
+	pubKey := zkverify.HashToField(a.PubKey)
+	sender := zkverify.HashToField(a.Sender)
+
+	assignment := &TransferCircuit{
+		Commitment: Commitment(a.Secret, userID, pubKey, sender),
+		UserID:     userID,
+		PubKey:     pubKey,
+		Sender:     sender,
+		Secret:     a.Secret,
+	}
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return Proof{}, fmt.Errorf("failed to build witness: %w", err)
+	}
+	proof, err := groth16.Prove(k.ccs, k.pk, w)
+	if err != nil {
+		return Proof{}, fmt.Errorf("failed to prove: %w", err)
+	}
+	pw, err := w.Public()
+	if err != nil {
+		return Proof{}, fmt.Errorf("failed to extract public witness: %w", err)
+	}
+
+	var proofBuf bytes.Buffer
+	if _, err := proof.WriteTo(&proofBuf); err != nil {
+		return Proof{}, fmt.Errorf("failed to serialize proof: %w", err)
+	}
+	pwBytes, err := pw.MarshalBinary()
+	if err != nil {
+		return Proof{}, fmt.Errorf("failed to serialize public witness: %w", err)
+	}
+
+	return Proof{
+		ProofB64: base64.StdEncoding.EncodeToString(proofBuf.Bytes()),
+		PubB64:   base64.StdEncoding.EncodeToString(pwBytes),
+	}, nil
+}
+
+// UserIDToField encodes a user ID the way production proofs carry it in public input 1.
+func UserIDToField(userID string) *big.Int {
+	v := new(big.Int).SetBytes([]byte(userID))
+	return v.Mod(v, ecc.BN254.ScalarField())
+}
+
+// Commitment computes MiMC(secret, userID, pubKey, sender) outside the circuit.
+func Commitment(secret, userID, pubKey, sender *big.Int) *big.Int {
+	h := nativemimc.NewMiMC()
+	for _, v := range []*big.Int{secret, userID, pubKey, sender} {
+		var e fr.Element
+		e.SetBigInt(v)
+		b := e.Bytes()
+		h.Write(b[:])
+	}
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
@@ -0,0 +1,69 @@
+package zkcircuit
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mezonai/mmn-sdk/go-sdk/zkverify"
+)
+
+func TestProveVerifiesWithZkVerify(t *testing.T) {
+	keys, err := Setup()
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	vkPath := filepath.Join(t.TempDir(), "vk.b64")
+	var vkBuf bytes.Buffer
+	if err := keys.WriteVerifyingKey(&vkBuf); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(vkPath, vkBuf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := zkverify.NewZkVerify(vkPath)
+	if err != nil {
+		t.Fatalf("NewZkVerify() error = %v", err)
+	}
+
+	a := Assignment{
+		UserID: "3767478432163172999",
+		Sender: "8BH3ZXoAptWYbAc69221kKDrrPzvc4RaJ248qdbTs6k5",
+		PubKey: "2Bq5iv3hxDf7Z8moNVmLzKKKFBWoV48BZ1M1ppqqRJ5j",
+		Secret: big.NewInt(42),
+	}
+	proof, err := keys.Prove(a)
+	if err != nil {
+		t.Fatalf("Prove() error = %v", err)
+	}
+	if err := verifier.VerifyDetailed(a.Sender, a.PubKey, proof.ProofB64, proof.PubB64); err != nil {
+		t.Fatalf("VerifyDetailed() = %v", err)
+	}
+
+	inputs, err := zkverify.DecodePublicInputs(proof.PubB64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inputs.UserIDString() != a.UserID {
+		t.Errorf("UserIDString() = %q, want %q", inputs.UserIDString(), a.UserID)
+	}
+
+	var pkBuf bytes.Buffer
+	if err := keys.WriteProvingKey(&pkBuf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&pkBuf, bytes.NewReader(vkBuf.Bytes()))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	again, err := loaded.Prove(a)
+	if err != nil {
+		t.Fatalf("Prove() with loaded keys error = %v", err)
+	}
+	if !verifier.Verify(a.Sender, a.PubKey, again.ProofB64, again.PubB64) {
+		t.Errorf("proof from loaded keys does not verify")
+	}
+}
@@ -0,0 +1,167 @@
+package zkverify
+
+import (
+	"container/list"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/allegro/bigcache"
+)
+
+const defaultResultTTL = 30 * time.Minute
+
+// CacheKey is the SHA-256 digest of a verification input.
+type CacheKey [32]byte
+
+// ResultCache stores verification results. Implementations must be safe for concurrent use
+// and must not return entries older than the ttl they were stored with.
+type ResultCache interface {
+	Get(key CacheKey) (valid bool, ok bool)
+	Set(key CacheKey, valid bool, ttl time.Duration)
+}
+
+// CacheStats counts result cache lookups.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// BigCacheConfig holds the bigcache settings used by NewBigCache.
+type BigCacheConfig struct {
+	Shards      int
+	LifeWindow  time.Duration
+	CleanWindow time.Duration
+	// HardMaxCacheSize limits the cache size in MB. Zero means no limit.
+	HardMaxCacheSize int
+}
+
+// DefaultBigCacheConfig returns the settings NewZkVerify has always used.
+func DefaultBigCacheConfig() BigCacheConfig {
+	return BigCacheConfig{
+		Shards:      128,
+		LifeWindow:  defaultResultTTL,
+		CleanWindow: 10 * time.Minute,
+	}
+}
+
+// BigCache is a ResultCache backed by bigcache. LifeWindow bounds every ttl; shorter
+// ttls are enforced with an expiry stored in the entry.
+type BigCache struct {
+	cache *bigcache.BigCache
+}
+
+func NewBigCache(cfg BigCacheConfig) (*BigCache, error) {
+	cache, err := bigcache.NewBigCache(bigcache.Config{
+		Shards:           cfg.Shards,
+		LifeWindow:       cfg.LifeWindow,
+		CleanWindow:      cfg.CleanWindow,
+		MaxEntrySize:     64,
+		Verbose:          false,
+		HardMaxCacheSize: cfg.HardMaxCacheSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bigcache: %w", err)
+	}
+	return &BigCache{cache: cache}, nil
+}
+
+func (c *BigCache) Get(key CacheKey) (bool, bool) {
+	entry, err := c.cache.Get(string(key[:]))
+	if err != nil || len(entry) != 9 {
+		return false, false
+	}
+	if time.Now().UnixNano() > int64(binary.BigEndian.Uint64(entry[1:])) {
+		return false, false
+	}
+	return entry[0] == 1, true
+}
+
+func (c *BigCache) Set(key CacheKey, valid bool, ttl time.Duration) {
+	var entry [9]byte
+	if valid {
+		entry[0] = 1
+	}
+	binary.BigEndian.PutUint64(entry[1:], uint64(time.Now().Add(ttl).UnixNano()))
+	_ = c.cache.Set(string(key[:]), entry[:])
+}
+
+// Reset removes all entries.
+func (c *BigCache) Reset() error {
+	return c.cache.Reset()
+}
+
+// LRUCache is a ResultCache holding at most a fixed number of entries, evicting the least recently used.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[CacheKey]*list.Element
+}
+
+type lruEntry struct {
+	key     CacheKey
+	valid   bool
+	expires time.Time
+}
+
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  make(map[CacheKey]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(key CacheKey) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return false, false
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return false, false
+	}
+	c.order.MoveToFront(el)
+	return entry.valid, true
+}
+
+func (c *LRUCache) Set(key CacheKey, valid bool, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.valid, entry.expires = valid, expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, valid: valid, expires: expires})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// NoopCache is a ResultCache that stores nothing.
+type NoopCache struct{}
+
+func (NoopCache) Get(CacheKey) (bool, bool) { return false, false }
+
+func (NoopCache) Set(CacheKey, bool, time.Duration) {}
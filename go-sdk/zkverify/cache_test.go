@@ -0,0 +1,85 @@
+package zkverify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	a, b, d := makeCacheKey(nil, "a", "", "", ""), makeCacheKey(nil, "b", "", "", ""), makeCacheKey(nil, "d", "", "", "")
+
+	c.Set(a, true, time.Minute)
+	c.Set(b, false, time.Minute)
+	if valid, ok := c.Get(a); !ok || !valid {
+		t.Fatalf("Get(a) = %v, %v", valid, ok)
+	}
+	c.Set(d, true, time.Minute)
+	if _, ok := c.Get(b); ok {
+		t.Errorf("least recently used entry was not evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+
+	c.Set(a, true, -time.Second)
+	if _, ok := c.Get(a); ok {
+		t.Errorf("expired entry was returned")
+	}
+}
+
+func TestBigCache_TTL(t *testing.T) {
+	c, err := NewBigCache(DefaultBigCacheConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := makeCacheKey(nil, "sender", "pubkey", "proof", "pub")
+
+	c.Set(key, false, time.Minute)
+	if valid, ok := c.Get(key); !ok || valid {
+		t.Errorf("Get() = %v, %v, want cached false", valid, ok)
+	}
+	c.Set(key, true, -time.Second)
+	if _, ok := c.Get(key); ok {
+		t.Errorf("expired entry was returned")
+	}
+}
+
+func TestMakeCacheKey(t *testing.T) {
+	if makeCacheKey(nil, "ab", "c", "", "") == makeCacheKey(nil, "a", "bc", "", "") {
+		t.Errorf("makeCacheKey() collides on field boundaries")
+	}
+}
+
+func TestZkVerify_CacheOptions(t *testing.T) {
+	f := loadFixture(t)
+	cache := NewLRUCache(16)
+	v, err := NewZkVerifyWithOptions(NewKeySet("v1", f.vk), Options{
+		Cache:       cache,
+		PositiveTTL: time.Hour,
+		NegativeTTL: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := f.prove(t, "sender", "pubkey")
+
+	v.Verify(in.Sender, in.PubKey, in.ProofB64, in.PubB64)
+	v.Verify(in.Sender, in.PubKey, in.ProofB64, in.PubB64)
+	v.Verify("mallory", in.PubKey, in.ProofB64, in.PubB64)
+	time.Sleep(time.Millisecond)
+	v.Verify("mallory", in.PubKey, in.ProofB64, in.PubB64)
+
+	if got := v.CacheStats(); got.Hits != 1 || got.Misses != 3 {
+		t.Errorf("CacheStats() = %+v, want 1 hit and 3 misses", got)
+	}
+
+	nv, err := NewZkVerifyWithOptions(NewKeySet("v1", f.vk), Options{Cache: NoopCache{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nv.Verify(in.Sender, in.PubKey, in.ProofB64, in.PubB64)
+	if _, ok := nv.cached(in.cacheKey(nv.Keys())); ok {
+		t.Errorf("NoopCache returned a result")
+	}
+}
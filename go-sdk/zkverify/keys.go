@@ -0,0 +1,252 @@
+package zkverify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// defaultMaxKeys keeps the current and the previous verifying key during a rotation.
+const defaultMaxKeys = 2
+
+var ErrNoVerifyingKey = errors.New("zkverify: no verifying key")
+
+// KeyEncoding is the text or binary encoding of a serialized verifying key.
+type KeyEncoding int
+
+const (
+	// EncodingBase64 is standard base64, the format read by NewZkVerify.
+	EncodingBase64 KeyEncoding = iota
+	EncodingHex
+	EncodingBinary
+)
+
+// ParseVerifyingKey decodes a BN254 Groth16 verifying key.
+func ParseVerifyingKey(data []byte, enc KeyEncoding) (groth16.VerifyingKey, error) {
+	raw, err := decodeKey(data, enc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode zk verifying key: %w", err)
+	}
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	if _, err := vk.ReadFrom(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("failed to read set verifying key: %w", err)
+	}
+	return vk, nil
+}
+
+// KeyID derives a stable identifier for a serialized key from its decoded bytes.
+func KeyID(data []byte, enc KeyEncoding) (string, error) {
+	raw, err := decodeKey(data, enc)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+func decodeKey(data []byte, enc KeyEncoding) ([]byte, error) {
+	switch enc {
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	case EncodingHex:
+		return hex.DecodeString(string(bytes.TrimSpace(data)))
+	case EncodingBinary:
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown key encoding %d", enc)
+	}
+}
+
+// NewZkVerifyFromBytes creates a verifier from a serialized verifying key.
+func NewZkVerifyFromBytes(data []byte, enc KeyEncoding) (*ZkVerify, error) {
+	id, err := KeyID(data, enc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode zk verifying key: %w", err)
+	}
+	vk, err := ParseVerifyingKey(data, enc)
+	if err != nil {
+		return nil, err
+	}
+	return NewZkVerifyWithKeys(NewKeySet(id, vk))
+}
+
+// NewZkVerifyFromReader creates a verifier from a verifying key read in full from r.
+func NewZkVerifyFromReader(r io.Reader, enc KeyEncoding) (*ZkVerify, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zk verifying key: %w", err)
+	}
+	return NewZkVerifyFromBytes(data, enc)
+}
+
+// NewZkVerifyFromFS creates a verifier from a key file in fsys, typically an embed.FS.
+func NewZkVerifyFromFS(fsys fs.FS, name string, enc KeyEncoding) (*ZkVerify, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zk verifying key: %w", err)
+	}
+	return NewZkVerifyFromBytes(data, enc)
+}
+
+// KeySet is an immutable list of verifying keys identified by ID or version, current key first.
+type KeySet struct {
+	ids  []string
+	keys []groth16.VerifyingKey
+	// digest identifies the serialized keys; it is set when a ZkVerify installs the set.
+	digest []byte
+}
+
+func NewKeySet(id string, vk groth16.VerifyingKey) *KeySet {
+	return &KeySet{ids: []string{id}, keys: []groth16.VerifyingKey{vk}}
+}
+
+// With returns a copy of the set with vk as the current key. An existing key with the same id is replaced.
+func (ks *KeySet) With(id string, vk groth16.VerifyingKey) *KeySet {
+	out := NewKeySet(id, vk)
+	for i, existing := range ks.ids {
+		if existing != id {
+			out.ids = append(out.ids, existing)
+			out.keys = append(out.keys, ks.keys[i])
+		}
+	}
+	return out
+}
+
+// Limit returns a copy of the set keeping at most the n most recent keys.
+func (ks *KeySet) Limit(n int) *KeySet {
+	if n <= 0 || n >= len(ks.ids) {
+		return ks
+	}
+	return &KeySet{ids: ks.ids[:n:n], keys: ks.keys[:n:n]}
+}
+
+func (ks *KeySet) Get(id string) (groth16.VerifyingKey, bool) {
+	for i, existing := range ks.ids {
+		if existing == id {
+			return ks.keys[i], true
+		}
+	}
+	return nil, false
+}
+
+// IDs returns key IDs, current first.
+func (ks *KeySet) IDs() []string {
+	return append([]string(nil), ks.ids...)
+}
+
+func (ks *KeySet) Len() int {
+	return len(ks.ids)
+}
+
+// verify accepts the proof if any key verifies it and otherwise reports the current key's error.
+func (ks *KeySet) verify(proof groth16.Proof, pw witness.Witness) error {
+	var firstErr error
+	for _, vk := range ks.keys {
+		err := groth16.Verify(proof, vk, pw)
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Keys returns the verifying keys currently in use.
+func (v *ZkVerify) Keys() *KeySet {
+	return v.keys.Load()
+}
+
+// SetMaxKeys sets how many keys Rotate retains, including the current one. The default is 2.
+func (v *ZkVerify) SetMaxKeys(n int) {
+	v.rotate.Lock()
+	defer v.rotate.Unlock()
+	v.maxKeys = n
+}
+
+// Rotate makes vk the current key, keeping previous keys up to the configured maximum.
+// The swap is atomic. Cached results are keyed by the key set, so results cached under
+// the previous set are not reused.
+func (v *ZkVerify) Rotate(id string, vk groth16.VerifyingKey) {
+	v.rotate.Lock()
+	defer v.rotate.Unlock()
+	v.install(v.keys.Load().With(id, vk).Limit(v.maxKeys))
+}
+
+// SetKeys atomically replaces the whole key set.
+func (v *ZkVerify) SetKeys(keys *KeySet) error {
+	if keys == nil || keys.Len() == 0 {
+		return ErrNoVerifyingKey
+	}
+	v.rotate.Lock()
+	defer v.rotate.Unlock()
+	v.install(keys)
+	return nil
+}
+
+// install makes a copy of ks the key set in use, with the digest of its serialized keys
+// that becomes part of every cache key. Callers hold v.rotate or own v exclusively.
+func (v *ZkVerify) install(ks *KeySet) {
+	h := sha256.New()
+	for _, vk := range ks.keys {
+		vk.WriteTo(h)
+	}
+	installed := *ks
+	installed.digest = h.Sum(nil)
+	v.keys.Store(&installed)
+}
+
+// WatchKeyFile polls path every interval and rotates to the key it contains whenever the
+// content changes. The key ID is derived with KeyID. Reload errors are passed to onError,
+// which may be nil, and the previous keys stay in use. It blocks until ctx is done.
+func (v *ZkVerify) WatchKeyFile(ctx context.Context, path string, enc KeyEncoding, interval time.Duration, onError func(error)) error {
+	report := func(err error) {
+		if onError != nil {
+			onError(err)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			report(fmt.Errorf("failed to read zk verifying key: %w", err))
+			continue
+		}
+		id, err := KeyID(data, enc)
+		if err != nil {
+			report(fmt.Errorf("failed to decode zk verifying key: %w", err))
+			continue
+		}
+		if ids := v.keys.Load().ids; ids[0] == id {
+			continue
+		}
+		vk, err := ParseVerifyingKey(data, enc)
+		if err != nil {
+			report(err)
+			continue
+		}
+		v.Rotate(id, vk)
+	}
+}
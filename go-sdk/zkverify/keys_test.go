@@ -0,0 +1,138 @@
+package zkverify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestNewZkVerify_Encodings(t *testing.T) {
+	f := loadFixture(t)
+	in := f.prove(t, "sender", "pubkey")
+
+	constructors := map[string]func() (*ZkVerify, error){
+		"base64 bytes": func() (*ZkVerify, error) {
+			return NewZkVerifyFromBytes([]byte(base64.StdEncoding.EncodeToString(f.vkRaw)+"\n"), EncodingBase64)
+		},
+		"hex bytes": func() (*ZkVerify, error) {
+			return NewZkVerifyFromBytes([]byte(hex.EncodeToString(f.vkRaw)), EncodingHex)
+		},
+		"binary reader": func() (*ZkVerify, error) {
+			return NewZkVerifyFromReader(bytes.NewReader(f.vkRaw), EncodingBinary)
+		},
+		"fs": func() (*ZkVerify, error) {
+			fsys := fstest.MapFS{"keys/vk.bin": {Data: f.vkRaw}}
+			return NewZkVerifyFromFS(fsys, "keys/vk.bin", EncodingBinary)
+		},
+	}
+	for name, newVerifier := range constructors {
+		v, err := newVerifier()
+		if err != nil {
+			t.Errorf("%s: error = %v", name, err)
+			continue
+		}
+		if !v.Verify(in.Sender, in.PubKey, in.ProofB64, in.PubB64) {
+			t.Errorf("%s: Verify() = false for a valid proof", name)
+		}
+	}
+
+	if _, err := NewZkVerifyFromBytes([]byte("zz"), EncodingHex); err == nil {
+		t.Errorf("NewZkVerifyFromBytes() accepted invalid hex")
+	}
+}
+
+func TestZkVerify_Rotate(t *testing.T) {
+	oldKey := loadFixture(t)
+	newKey, err := setupFixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldProof := oldKey.prove(t, "sender-old", "pubkey")
+	newProof := newKey.prove(t, "sender-new", "pubkey")
+
+	v, err := NewZkVerifyWithKeys(NewKeySet("v1", oldKey.vk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Verify(newProof.Sender, newProof.PubKey, newProof.ProofB64, newProof.PubB64) {
+		t.Fatalf("Verify() accepted a proof for an unknown key")
+	}
+
+	v.Rotate("v2", newKey.vk)
+	if got := v.Keys().IDs(); len(got) != 2 || got[0] != "v2" || got[1] != "v1" {
+		t.Fatalf("Keys().IDs() = %v, want [v2 v1]", got)
+	}
+	// The rejection cached above must not outlive the rotation.
+	if !v.Verify(newProof.Sender, newProof.PubKey, newProof.ProofB64, newProof.PubB64) {
+		t.Errorf("Verify() with current key = false")
+	}
+	if !v.Verify(oldProof.Sender, oldProof.PubKey, oldProof.ProofB64, oldProof.PubB64) {
+		t.Errorf("Verify() with previous key = false")
+	}
+	if err := v.VerifyDetailed(newProof.Sender, newProof.PubKey, newProof.ProofB64, newProof.PubB64); err != nil {
+		t.Errorf("VerifyDetailed() with current key = %v", err)
+	}
+
+	v.Rotate("v3", newKey.vk)
+	if _, ok := v.Keys().Get("v1"); ok {
+		t.Errorf("Rotate() kept more than two keys: %v", v.Keys().IDs())
+	}
+	// Nor may the acceptance cached while v1 was still in use.
+	if v.Verify(oldProof.Sender, oldProof.PubKey, oldProof.ProofB64, oldProof.PubB64) {
+		t.Errorf("Verify() accepted a proof of a retired key")
+	}
+	if err := v.VerifyDetailed(oldProof.Sender, oldProof.PubKey, oldProof.ProofB64, oldProof.PubB64); FailureStage(err) != StageGroth16 {
+		t.Errorf("VerifyDetailed() with retired key = %v, want groth16 failure", err)
+	}
+}
+
+func TestZkVerify_WatchKeyFile(t *testing.T) {
+	oldKey := loadFixture(t)
+	newKey, err := setupFixture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "vk.b64")
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(oldKey.vkRaw)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewZkVerify(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached := oldKey.prove(t, "cached", "pubkey")
+	v.Verify(cached.Sender, cached.PubKey, cached.ProofB64, cached.PubB64)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go v.WatchKeyFile(ctx, path, EncodingBase64, 5*time.Millisecond, nil)
+
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(newKey.vkRaw)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wantID, _ := KeyID(newKey.vkRaw, EncodingBinary)
+	deadline := time.Now().Add(5 * time.Second)
+	for v.Keys().IDs()[0] != wantID {
+		if time.Now().After(deadline) {
+			t.Fatalf("key file change not picked up, keys = %v", v.Keys().IDs())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, ok := v.cached(cached.cacheKey(v.Keys())); ok {
+		t.Errorf("a result cached under the previous keys is reused after the reload")
+	}
+	if !v.Verify(cached.Sender, cached.PubKey, cached.ProofB64, cached.PubB64) {
+		t.Errorf("Verify() = false for a proof of the previous key")
+	}
+	in := newKey.prove(t, "sender", "pubkey")
+	if !v.Verify(in.Sender, in.PubKey, in.ProofB64, in.PubB64) {
+		t.Errorf("Verify() = false for a proof of the reloaded key")
+	}
+}
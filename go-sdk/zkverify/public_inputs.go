@@ -0,0 +1,139 @@
+package zkverify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+)
+
+// NumPublicInputs is the number of public inputs of the ZK transfer circuit.
+const NumPublicInputs = 4
+
+const (
+	publicInputCommitment = iota
+	publicInputUserID
+	publicInputPubKey
+	publicInputSender
+)
+
+// PublicInputs are the decoded public inputs of a ZK transfer proof, in circuit order:
+//
+//	0: a circuit commitment the SDK does not interpret
+//	1: the user ID, as its ASCII bytes read as a big-endian integer
+//	2: the signing public key string, reduced mod BN254 (see HashToField)
+//	3: the sender address string, reduced mod BN254
+//
+// None of the inputs carries an expiry or a nonce; replay protection comes from the
+// transaction nonce covered by the signature.
+type PublicInputs struct {
+	values [NumPublicInputs]fr.Element
+}
+
+// DecodePublicInputs decodes a base64 public witness as carried in Tx.ZkPub.
+// Errors are *VerifyError values with the stage that failed.
+func DecodePublicInputs(pubB64 string) (PublicInputs, error) {
+	pwBytes, err := base64.StdEncoding.DecodeString(pubB64)
+	if err != nil {
+		return PublicInputs{}, stageError(StageDecodePublic, err)
+	}
+	return ParsePublicInputs(pwBytes)
+}
+
+// ParsePublicInputs decodes a binary public witness.
+func ParsePublicInputs(data []byte) (PublicInputs, error) {
+	pw, err := readPublicWitness(data)
+	if err != nil {
+		return PublicInputs{}, err
+	}
+	return PublicInputsFromWitness(pw)
+}
+
+func readPublicWitness(data []byte) (witness.Witness, error) {
+	pw, err := frontend.NewWitness(nil, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, stageError(StageParsePublic, err)
+	}
+	if _, err := pw.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, stageError(StageParsePublic, err)
+	}
+	return pw, nil
+}
+
+// PublicInputsFromWitness reads the public inputs from a BN254 public witness.
+func PublicInputsFromWitness(pw witness.Witness) (PublicInputs, error) {
+	vector, ok := pw.Vector().(fr.Vector)
+	if !ok {
+		return PublicInputs{}, stageError(StageParsePublic, fmt.Errorf("unexpected witness vector %T", pw.Vector()))
+	}
+	if len(vector) != NumPublicInputs {
+		return PublicInputs{}, &VerifyError{Stage: StagePublicCount, Index: -1, Expected: strconv.Itoa(NumPublicInputs), Actual: strconv.Itoa(len(vector))}
+	}
+
+	var p PublicInputs
+	copy(p.values[:], vector)
+	return p, nil
+}
+
+// HashToField maps a string to a BN254 scalar the way the circuit binds the sender and public key.
+func HashToField(s string) *big.Int {
+	return stringToBigIntBN254FromBytes(s)
+}
+
+func (p PublicInputs) Commitment() *big.Int {
+	return p.value(publicInputCommitment)
+}
+
+func (p PublicInputs) UserID() *big.Int {
+	return p.value(publicInputUserID)
+}
+
+// UserIDString returns the user ID as text, e.g. "3767478432163172999".
+func (p PublicInputs) UserIDString() string {
+	return string(p.UserID().Bytes())
+}
+
+func (p PublicInputs) PubKeyHash() *big.Int {
+	return p.value(publicInputPubKey)
+}
+
+func (p PublicInputs) SenderHash() *big.Int {
+	return p.value(publicInputSender)
+}
+
+// Values returns all public inputs in circuit order.
+func (p PublicInputs) Values() []*big.Int {
+	out := make([]*big.Int, NumPublicInputs)
+	for i := range out {
+		out[i] = p.value(i)
+	}
+	return out
+}
+
+// CheckBinding reports a *VerifyError if the inputs do not bind to sender and pubKey.
+// It is the pre-proof check of VerifyDetailed and needs no verifying key.
+func (p PublicInputs) CheckBinding(sender, pubKey string) error {
+	if err := p.checkInput(publicInputSender, StageSenderMismatch, sender); err != nil {
+		return err
+	}
+	return p.checkInput(publicInputPubKey, StagePubKeyMismatch, pubKey)
+}
+
+func (p PublicInputs) checkInput(index int, stage Stage, s string) error {
+	var expected fr.Element
+	expected.SetBigInt(HashToField(s))
+	if p.values[index].Equal(&expected) {
+		return nil
+	}
+	return &VerifyError{Stage: stage, Index: index, Expected: expected.String(), Actual: p.values[index].String()}
+}
+
+func (p PublicInputs) value(i int) *big.Int {
+	return p.values[i].BigInt(new(big.Int))
+}
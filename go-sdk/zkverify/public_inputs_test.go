@@ -0,0 +1,38 @@
+package zkverify
+
+import (
+	"testing"
+)
+
+// samplePub is the public witness of the ZK transfer used in client.TestClient_SendToken.
+const samplePub = "AAAABAAAAAAAAAAEGXCTN8ZNN3H071Ika7f1l+1tIUWLtsQ5FaXqv9c2L7cAAAAAAAAAAAAAAAAAMzc2NzQ3ODQzMjE2MzE3Mjk5OSSG388WlTRfAZ3r3w6bu8EmVbNlf9dyuS25NeJwx9gmKur1plPc1AnRoYtefb3mzUMHLgWArBdW8RKHMQ8NeV4="
+
+func TestDecodePublicInputs(t *testing.T) {
+	p, err := DecodePublicInputs(samplePub)
+	if err != nil {
+		t.Fatalf("DecodePublicInputs() error = %v", err)
+	}
+
+	if got := p.UserIDString(); got != "3767478432163172999" {
+		t.Errorf("UserIDString() = %q", got)
+	}
+	if err := p.CheckBinding("8BH3ZXoAptWYbAc69221kKDrrPzvc4RaJ248qdbTs6k5", "2Bq5iv3hxDf7Z8moNVmLzKKKFBWoV48BZ1M1ppqqRJ5j"); err != nil {
+		t.Errorf("CheckBinding() = %v", err)
+	}
+	if err := p.CheckBinding("8BH3ZXoAptWYbAc69221kKDrrPzvc4RaJ248qdbTs6k5", "other"); FailureStage(err) != StagePubKeyMismatch {
+		t.Errorf("CheckBinding() = %v, want pubkey mismatch", err)
+	}
+	if got := p.SenderHash(); got.Cmp(HashToField("8BH3ZXoAptWYbAc69221kKDrrPzvc4RaJ248qdbTs6k5")) != 0 {
+		t.Errorf("SenderHash() = %s", got)
+	}
+	if len(p.Values()) != NumPublicInputs || p.Values()[0].Cmp(p.Commitment()) != 0 {
+		t.Errorf("Values() = %v", p.Values())
+	}
+
+	if _, err := DecodePublicInputs("!!"); FailureStage(err) != StageDecodePublic {
+		t.Errorf("DecodePublicInputs(!!) = %v, want decode_public", err)
+	}
+	if _, err := ParsePublicInputs([]byte{0, 0, 0, 1}); FailureStage(err) != StageParsePublic {
+		t.Errorf("ParsePublicInputs(short) = %v, want parse_public", err)
+	}
+}
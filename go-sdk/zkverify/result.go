@@ -0,0 +1,113 @@
+package zkverify
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Stage names the verification step at which a proof was rejected.
+type Stage string
+
+const (
+	StageDecodeProof    Stage = "decode_proof"
+	StageParseProof     Stage = "parse_proof"
+	StageDecodePublic   Stage = "decode_public"
+	StageParsePublic    Stage = "parse_public"
+	StagePublicCount    Stage = "public_count"
+	StageSenderMismatch Stage = "sender_mismatch"
+	StagePubKeyMismatch Stage = "pubkey_mismatch"
+	StageGroth16        Stage = "groth16"
+)
+
+// VerifyError is returned by VerifyDetailed for a rejected proof.
+// For StagePublicCount and the mismatch stages, Expected and Actual hold the
+// public input count or the public input value at Index.
+type VerifyError struct {
+	Stage    Stage
+	Index    int
+	Expected string
+	Actual   string
+	Err      error
+}
+
+func (e *VerifyError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("zkverify: %s: %v", e.Stage, e.Err)
+	case e.Index >= 0:
+		return fmt.Sprintf("zkverify: %s at public input %d: expected %s, got %s", e.Stage, e.Index, e.Expected, e.Actual)
+	default:
+		return fmt.Sprintf("zkverify: %s: expected %s, got %s", e.Stage, e.Expected, e.Actual)
+	}
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+func stageError(stage Stage, err error) *VerifyError {
+	return &VerifyError{Stage: stage, Index: -1, Err: err}
+}
+
+// FailureStage returns the stage of a VerifyDetailed error, or "" if err is not a *VerifyError.
+func FailureStage(err error) Stage {
+	var verr *VerifyError
+	if errors.As(err, &verr) {
+		return verr.Stage
+	}
+	return ""
+}
+
+// Metrics receives the outcome of every proof that is actually verified; cache hits are not reported.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	ObserveSuccess()
+	ObserveFailure(stage Stage)
+}
+
+// SetMetrics installs m as the metrics hook. It must be called before the verifier is shared.
+func (v *ZkVerify) SetMetrics(m Metrics) {
+	v.metrics = m
+}
+
+func (v *ZkVerify) observe(err error) {
+	if v.metrics == nil {
+		return
+	}
+	if err == nil {
+		v.metrics.ObserveSuccess()
+		return
+	}
+	v.metrics.ObserveFailure(FailureStage(err))
+}
+
+// Counters is an in-memory Metrics implementation counting outcomes by stage.
+type Counters struct {
+	success  atomic.Uint64
+	failures sync.Map // Stage -> *atomic.Uint64
+}
+
+func (c *Counters) ObserveSuccess() {
+	c.success.Add(1)
+}
+
+func (c *Counters) ObserveFailure(stage Stage) {
+	n, _ := c.failures.LoadOrStore(stage, new(atomic.Uint64))
+	n.(*atomic.Uint64).Add(1)
+}
+
+func (c *Counters) Success() uint64 {
+	return c.success.Load()
+}
+
+// Failures returns a snapshot of failure counts by stage.
+func (c *Counters) Failures() map[Stage]uint64 {
+	out := make(map[Stage]uint64)
+	c.failures.Range(func(k, v any) bool {
+		out[k.(Stage)] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	return out
+}
@@ -2,23 +2,60 @@ package zkverify
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
+	"encoding/binary"
 	"fmt"
 	"math/big"
 	"os"
-	"strings"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/allegro/bigcache"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
 )
 
 type ZkVerify struct {
-	vk      groth16.VerifyingKey
-	zkCache *bigcache.BigCache
+	keys        atomic.Pointer[KeySet]
+	rotate      sync.Mutex
+	maxKeys     int
+	cache       ResultCache
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	workers     int
+	metrics     Metrics
+}
+
+// Options configures a ZkVerify. Zero values select the defaults.
+type Options struct {
+	// Cache stores verification results. Defaults to a bigcache built from DefaultBigCacheConfig.
+	Cache ResultCache
+	// PositiveTTL is how long a valid result is cached. Defaults to 30 minutes.
+	PositiveTTL time.Duration
+	// NegativeTTL is how long an invalid result is cached. Defaults to PositiveTTL.
+	NegativeTTL time.Duration
+	// Workers is the VerifyBatch pool size. Defaults to GOMAXPROCS.
+	Workers int
+}
+
+// ProofInput is a ZK transfer proof together with the sender and public key it must bind to.
+type ProofInput struct {
+	Sender   string
+	PubKey   string
+	ProofB64 string
+	PubB64   string
+}
+
+// BatchResult is the outcome of one ProofInput of VerifyBatch.
+// Err is set to the context error when the input was not verified before cancellation.
+type BatchResult struct {
+	Valid bool
+	Err   error
 }
 
 func NewZkVerify(keyPath string) (*ZkVerify, error) {
@@ -26,109 +63,226 @@ func NewZkVerify(keyPath string) (*ZkVerify, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read zk verifying key: %w", err)
 	}
-	vkBytes, err := base64.StdEncoding.DecodeString(string(vkB64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode zk verifying key: %w", err)
-	}
-	vk := groth16.NewVerifyingKey(ecc.BN254)
-	if _, err := vk.ReadFrom(bytes.NewReader(vkBytes)); err != nil {
-		return nil, fmt.Errorf("failed to read set verifying key: %w", err)
+	return NewZkVerifyFromBytes(vkB64, EncodingBase64)
+}
+
+// NewZkVerifyWithKeys creates a verifier that accepts proofs for any key in keys.
+func NewZkVerifyWithKeys(keys *KeySet) (*ZkVerify, error) {
+	return NewZkVerifyWithOptions(keys, Options{})
+}
+
+func NewZkVerifyWithOptions(keys *KeySet, opts Options) (*ZkVerify, error) {
+	if keys == nil || keys.Len() == 0 {
+		return nil, ErrNoVerifyingKey
 	}
 
-	cacheConfig := bigcache.Config{
-		Shards:           128,
-		LifeWindow:       30 * time.Minute,
-		CleanWindow:      10 * time.Minute,
-		MaxEntrySize:     64,
-		Verbose:          false,
-		HardMaxCacheSize: 0,
+	if opts.PositiveTTL <= 0 {
+		opts.PositiveTTL = defaultResultTTL
 	}
-	zkCache, err := bigcache.NewBigCache(cacheConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create bigcache: %w", err)
+	if opts.NegativeTTL <= 0 {
+		opts.NegativeTTL = opts.PositiveTTL
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.Cache == nil {
+		cfg := DefaultBigCacheConfig()
+		cfg.LifeWindow = max(opts.PositiveTTL, opts.NegativeTTL)
+		zkCache, err := NewBigCache(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts.Cache = zkCache
 	}
 
 	zv := &ZkVerify{
-		vk:      vk,
-		zkCache: zkCache,
+		cache:       opts.Cache,
+		positiveTTL: opts.PositiveTTL,
+		negativeTTL: opts.NegativeTTL,
+		workers:     opts.Workers,
+		maxKeys:     defaultMaxKeys,
 	}
+	zv.install(keys)
 
 	return zv, nil
 }
 
-func makeCacheKey(sender, pubKey, proofB64, pubB64 string) string {
-	return sender + "|" + pubKey + "|" + proofB64 + "|" + pubB64
+// makeCacheKey digests the key set digest and the inputs with a length prefix per field so
+// that distinct inputs never collide by concatenation and results do not outlive a key rotation.
+func makeCacheKey(keys []byte, sender, pubKey, proofB64, pubB64 string) CacheKey {
+	h := sha256.New()
+	var n [8]byte
+	for _, field := range []string{string(keys), sender, pubKey, proofB64, pubB64} {
+		binary.BigEndian.PutUint64(n[:], uint64(len(field)))
+		h.Write(n[:])
+		h.Write([]byte(field))
+	}
+	var key CacheKey
+	h.Sum(key[:0])
+	return key
+}
+
+func (in ProofInput) cacheKey(ks *KeySet) CacheKey {
+	return makeCacheKey(ks.digest, in.Sender, in.PubKey, in.ProofB64, in.PubB64)
 }
 
 func (v *ZkVerify) Verify(sender, pubKey, proofB64, pubB64 string) bool {
-	cacheKey := makeCacheKey(sender, pubKey, proofB64, pubB64)
+	ks := v.keys.Load()
+	cacheKey := makeCacheKey(ks.digest, sender, pubKey, proofB64, pubB64)
+
+	if result, ok := v.cached(cacheKey); ok {
+		return result
+	}
+
+	result := v.verifyInternal(ks, sender, pubKey, proofB64, pubB64)
+	v.store(cacheKey, result)
+
+	return result
+}
+
+// VerifyDetailed verifies a proof like Verify but reports why it was rejected.
+// It returns nil for a valid proof and a *VerifyError otherwise. The cache is
+// bypassed for the lookup so that a rejection always carries its reason.
+func (v *ZkVerify) VerifyDetailed(sender, pubKey, proofB64, pubB64 string) error {
+	ks := v.keys.Load()
+	err := v.verifyDetailed(ks, sender, pubKey, proofB64, pubB64)
+	v.observe(err)
+	v.store(makeCacheKey(ks.digest, sender, pubKey, proofB64, pubB64), err == nil)
+	return err
+}
+
+// VerifyContext is Verify for a ProofInput that returns early if ctx is already done.
+func (v *ZkVerify) VerifyContext(ctx context.Context, in ProofInput) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return v.Verify(in.Sender, in.PubKey, in.ProofB64, in.PubB64), nil
+}
+
+// VerifyBatch verifies inputs on a pool of Options.Workers workers and returns one result per input,
+// in order. Identical inputs are verified once, and inputs already in the cache are not
+// verified again. When ctx is cancelled, inputs not yet handed to a worker get ctx.Err().
+func (v *ZkVerify) VerifyBatch(ctx context.Context, inputs []ProofInput) []BatchResult {
+	results := make([]BatchResult, len(inputs))
+	ks := v.keys.Load()
 
-	cacheBytes, err := v.zkCache.Get(cacheKey)
-	if err == nil {
-		var cacheResult bool
-		if err := json.Unmarshal(cacheBytes, &cacheResult); err == nil {
-			return cacheResult
+	groups := make(map[CacheKey][]int)
+	var pending []CacheKey
+	for i, in := range inputs {
+		key := in.cacheKey(ks)
+		if result, ok := v.cached(key); ok {
+			results[i] = BatchResult{Valid: result}
+			continue
 		}
+		if _, seen := groups[key]; !seen {
+			pending = append(pending, key)
+		}
+		groups[key] = append(groups[key], i)
 	}
 
-	result := v.verifyInternal(sender, pubKey, proofB64, pubB64)
+	jobs := make(chan CacheKey)
+	var wg sync.WaitGroup
+	for w := 0; w < min(v.workers, len(pending)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				in := inputs[groups[key][0]]
+				result := v.verifyInternal(ks, in.Sender, in.PubKey, in.ProofB64, in.PubB64)
+				v.store(key, result)
+				for _, i := range groups[key] {
+					results[i] = BatchResult{Valid: result}
+				}
+			}
+		}()
+	}
 
-	resultBytes, _ := json.Marshal(result)
-	_ = v.zkCache.Set(cacheKey, resultBytes)
+	sent := 0
+feed:
+	for _, key := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- key:
+			sent++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
 
-	return result
+	for _, key := range pending[sent:] {
+		for _, i := range groups[key] {
+			results[i] = BatchResult{Err: ctx.Err()}
+		}
+	}
+
+	return results
 }
 
-func (v *ZkVerify) verifyInternal(sender, pubKey, proofB64, pubB64 string) bool {
+func (v *ZkVerify) cached(key CacheKey) (bool, bool) {
+	result, ok := v.cache.Get(key)
+	if ok {
+		v.hits.Add(1)
+	} else {
+		v.misses.Add(1)
+	}
+	return result, ok
+}
+
+func (v *ZkVerify) store(key CacheKey, result bool) {
+	ttl := v.positiveTTL
+	if !result {
+		ttl = v.negativeTTL
+	}
+	v.cache.Set(key, result, ttl)
+}
+
+// CacheStats returns the cache hit and miss counts of Verify and VerifyBatch lookups.
+func (v *ZkVerify) CacheStats() CacheStats {
+	return CacheStats{Hits: v.hits.Load(), Misses: v.misses.Load()}
+}
+
+func (v *ZkVerify) verifyInternal(ks *KeySet, sender, pubKey, proofB64, pubB64 string) bool {
+	err := v.verifyDetailed(ks, sender, pubKey, proofB64, pubB64)
+	v.observe(err)
+	return err == nil
+}
+
+func (v *ZkVerify) verifyDetailed(ks *KeySet, sender, pubKey, proofB64, pubB64 string) error {
 	proofBytes, err := base64.StdEncoding.DecodeString(proofB64)
 	if err != nil {
-		return false
+		return stageError(StageDecodeProof, err)
 	}
 	proof := groth16.NewProof(ecc.BN254)
 	_, err = proof.ReadFrom(bytes.NewReader(proofBytes))
 	if err != nil {
-		return false
+		return stageError(StageParseProof, err)
 	}
 
 	pwBytes, err := base64.StdEncoding.DecodeString(pubB64)
 	if err != nil {
-		return false
+		return stageError(StageDecodePublic, err)
 	}
-	pw, err := frontend.NewWitness(nil, ecc.BN254.ScalarField())
+	pw, err := readPublicWitness(pwBytes)
 	if err != nil {
-		return false
-	}
-	if _, err := pw.ReadFrom(bytes.NewReader(pwBytes)); err != nil {
-		return false
+		return err
 	}
-
-	pubVector := pw.Vector()
-	var pubString = fmt.Sprintf("%v", pubVector)
-	pubStringTrimmed := strings.TrimSpace(strings.Trim(pubString, "[]"))
-	var pubStringArray = []string{}
-	if pubStringTrimmed != "" {
-		pubStringArray = strings.Split(pubStringTrimmed, ",")
-		for i := range pubStringArray {
-			pubStringArray[i] = strings.TrimSpace(pubStringArray[i])
-		}
-	}
-
-	if len(pubStringArray) != 4 {
-		return false
-	}
-
-	if pubStringArray[3] != stringToBigIntBN254FromBytes(sender).String() {
-		return false
+	inputs, err := PublicInputsFromWitness(pw)
+	if err != nil {
+		return err
 	}
-	if pubStringArray[2] != stringToBigIntBN254FromBytes(pubKey).String() {
-		return false
+	if err := inputs.CheckBinding(sender, pubKey); err != nil {
+		return err
 	}
 
-	if err := groth16.Verify(proof, v.vk, pw); err != nil {
-		return false
+	if err := ks.verify(proof, pw); err != nil {
+		return stageError(StageGroth16, err)
 	}
 
-	return true
+	return nil
 }
 
 func stringToBigIntBN254FromBytes(s string) *big.Int {
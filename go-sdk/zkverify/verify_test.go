@@ -0,0 +1,269 @@
+package zkverify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// testCircuit has the public input layout checked by verifyInternal.
+type testCircuit struct {
+	Commitment frontend.Variable `gnark:",public"`
+	UserID     frontend.Variable `gnark:",public"`
+	PubKey     frontend.Variable `gnark:",public"`
+	Sender     frontend.Variable `gnark:",public"`
+	Secret     frontend.Variable
+}
+
+func (c *testCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.Secret, c.UserID), c.Commitment)
+	api.AssertIsDifferent(c.PubKey, 0)
+	api.AssertIsDifferent(c.Sender, 0)
+	return nil
+}
+
+type testFixture struct {
+	ccs    constraint.ConstraintSystem
+	pk     groth16.ProvingKey
+	vk     groth16.VerifyingKey
+	vkRaw  []byte
+	vkPath string
+}
+
+var (
+	fixtureOnce sync.Once
+	fixture     testFixture
+	fixtureErr  error
+)
+
+func loadFixture(tb testing.TB) testFixture {
+	tb.Helper()
+	fixtureOnce.Do(func() {
+		fixture, fixtureErr = setupFixture()
+	})
+	if fixtureErr != nil {
+		tb.Fatalf("setup fixture: %v", fixtureErr)
+	}
+	return fixture
+}
+
+// setupFixture runs a fresh trusted setup, so each call yields a different verifying key.
+func setupFixture() (testFixture, error) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &testCircuit{})
+	if err != nil {
+		return testFixture{}, err
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return testFixture{}, err
+	}
+	var buf bytes.Buffer
+	if _, err := vk.WriteTo(&buf); err != nil {
+		return testFixture{}, err
+	}
+	dir, err := os.MkdirTemp("", "zkverify")
+	if err != nil {
+		return testFixture{}, err
+	}
+	vkPath := filepath.Join(dir, "vk.b64")
+	if err := os.WriteFile(vkPath, []byte(base64.StdEncoding.EncodeToString(buf.Bytes())), 0o600); err != nil {
+		return testFixture{}, err
+	}
+	return testFixture{ccs: ccs, pk: pk, vk: vk, vkRaw: buf.Bytes(), vkPath: vkPath}, nil
+}
+
+func (f testFixture) prove(tb testing.TB, sender, pubKey string) ProofInput {
+	tb.Helper()
+	assignment := &testCircuit{
+		Commitment: 21,
+		UserID:     7,
+		PubKey:     stringToBigIntBN254FromBytes(pubKey),
+		Sender:     stringToBigIntBN254FromBytes(sender),
+		Secret:     3,
+	}
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	proof, err := groth16.Prove(f.ccs, f.pk, w)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pw, err := w.Public()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var proofBuf bytes.Buffer
+	if _, err := proof.WriteTo(&proofBuf); err != nil {
+		tb.Fatal(err)
+	}
+	pwBytes, err := pw.MarshalBinary()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return ProofInput{
+		Sender:   sender,
+		PubKey:   pubKey,
+		ProofB64: base64.StdEncoding.EncodeToString(proofBuf.Bytes()),
+		PubB64:   base64.StdEncoding.EncodeToString(pwBytes),
+	}
+}
+
+func (f testFixture) inputs(tb testing.TB, n int) []ProofInput {
+	tb.Helper()
+	inputs := make([]ProofInput, n)
+	for i := range inputs {
+		inputs[i] = f.prove(tb, fmt.Sprintf("sender-%d", i), fmt.Sprintf("pubkey-%d", i))
+	}
+	return inputs
+}
+
+func newTestVerifier(tb testing.TB) (*ZkVerify, testFixture) {
+	tb.Helper()
+	f := loadFixture(tb)
+	v, err := NewZkVerify(f.vkPath)
+	if err != nil {
+		tb.Fatalf("NewZkVerify() error = %v", err)
+	}
+	return v, f
+}
+
+func TestVerify(t *testing.T) {
+	v, f := newTestVerifier(t)
+	in := f.prove(t, "sender", "pubkey")
+
+	if !v.Verify(in.Sender, in.PubKey, in.ProofB64, in.PubB64) {
+		t.Fatalf("Verify() = false for a valid proof")
+	}
+	if v.Verify("other", in.PubKey, in.ProofB64, in.PubB64) {
+		t.Errorf("Verify() = true for a sender mismatch")
+	}
+	if v.Verify(in.Sender, "other", in.ProofB64, in.PubB64) {
+		t.Errorf("Verify() = true for a pubkey mismatch")
+	}
+	if v.Verify(in.Sender, in.PubKey, "!!", in.PubB64) {
+		t.Errorf("Verify() = true for a malformed proof")
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	v, f := newTestVerifier(t)
+	inputs := f.inputs(t, 3)
+	bad := inputs[1]
+	bad.Sender = "mallory"
+
+	batch := []ProofInput{inputs[0], bad, inputs[2], inputs[0], inputs[2]}
+	results := v.VerifyBatch(context.Background(), batch)
+
+	want := []bool{true, false, true, true, true}
+	for i, r := range results {
+		if r.Err != nil || r.Valid != want[i] {
+			t.Errorf("results[%d] = %+v, want Valid=%v", i, r, want[i])
+		}
+	}
+
+	if _, ok := v.cached(inputs[2].cacheKey(v.Keys())); !ok {
+		t.Errorf("VerifyBatch() did not populate the cache")
+	}
+}
+
+func TestVerifyBatch_Cancelled(t *testing.T) {
+	v, f := newTestVerifier(t)
+	inputs := f.inputs(t, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i, r := range v.VerifyBatch(ctx, inputs) {
+		if r.Err != context.Canceled || r.Valid {
+			t.Errorf("results[%d] = %+v, want context.Canceled", i, r)
+		}
+	}
+
+	if _, err := v.VerifyContext(ctx, inputs[0]); err != context.Canceled {
+		t.Errorf("VerifyContext() error = %v, want context.Canceled", err)
+	}
+}
+
+const benchBatchSize = 32
+
+func BenchmarkVerifySequential(b *testing.B) {
+	v, f := newTestVerifier(b)
+	inputs := f.inputs(b, benchBatchSize)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = v.cache.(*BigCache).Reset()
+		for _, in := range inputs {
+			v.Verify(in.Sender, in.PubKey, in.ProofB64, in.PubB64)
+		}
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	v, f := newTestVerifier(b)
+	inputs := f.inputs(b, benchBatchSize)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = v.cache.(*BigCache).Reset()
+		v.VerifyBatch(context.Background(), inputs)
+	}
+}
+
+func TestVerifyDetailed(t *testing.T) {
+	v, f := newTestVerifier(t)
+	counters := &Counters{}
+	v.SetMetrics(counters)
+	in := f.prove(t, "sender", "pubkey")
+
+	if err := v.VerifyDetailed(in.Sender, in.PubKey, in.ProofB64, in.PubB64); err != nil {
+		t.Fatalf("VerifyDetailed() = %v for a valid proof", err)
+	}
+
+	other := f.prove(t, "sender", "other")
+	cases := []struct {
+		name                             string
+		sender, pubKey, proofB64, pubB64 string
+		stage                            Stage
+	}{
+		{"bad proof base64", in.Sender, in.PubKey, "!!", in.PubB64, StageDecodeProof},
+		{"truncated proof", in.Sender, in.PubKey, base64.StdEncoding.EncodeToString([]byte{1, 2}), in.PubB64, StageParseProof},
+		{"bad public base64", in.Sender, in.PubKey, in.ProofB64, "!!", StageDecodePublic},
+		{"sender mismatch", "mallory", in.PubKey, in.ProofB64, in.PubB64, StageSenderMismatch},
+		{"pubkey mismatch", in.Sender, in.PubKey, in.ProofB64, other.PubB64, StagePubKeyMismatch},
+		{"proof for other inputs", in.Sender, "other", in.ProofB64, other.PubB64, StageGroth16},
+	}
+	for _, c := range cases {
+		err := v.VerifyDetailed(c.sender, c.pubKey, c.proofB64, c.pubB64)
+		if got := FailureStage(err); got != c.stage {
+			t.Errorf("%s: stage = %q (%v), want %q", c.name, got, err, c.stage)
+		}
+	}
+
+	var verr *VerifyError
+	err := v.VerifyDetailed("mallory", in.PubKey, in.ProofB64, in.PubB64)
+	if !errors.As(err, &verr) || verr.Index != 3 || verr.Expected != stringToBigIntBN254FromBytes("mallory").String() {
+		t.Errorf("VerifyDetailed() = %#v, want sender mismatch at index 3", err)
+	}
+
+	if counters.Success() != 1 {
+		t.Errorf("Counters.Success() = %d, want 1", counters.Success())
+	}
+	if got := counters.Failures()[StageSenderMismatch]; got != 2 {
+		t.Errorf("Counters.Failures()[sender_mismatch] = %d, want 2", got)
+	}
+}